@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -37,7 +38,26 @@ func main() {
 	svc := service.New(repo, cfg)
 	h := handler.New(svc, cfg)
 
-	go svc.StartMessageSender()
+	// Restore the sender state shared by all replicas and follow its changes
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := svc.RestoreSenderState(ctx); err != nil {
+		log.Fatalf("Failed to restore message sender state: %v", err)
+	}
+	go svc.WatchSenderState(ctx)
+
+	// Relay the lifecycle events of every replica to the local /events streams
+	go svc.RunEventHub(ctx)
+
+	// Wake the sender on new messages instead of waiting for the ticker
+	go svc.ListenForNewMessages(ctx)
+
+	// Status callbacks are delivered by every replica, claims keep them from delivering twice
+	svc.StartCallbackDispatcher()
+
+	// Only the leader runs the sender ticker when leader election is enabled
+	svc.StartLeaderElection()
 
 	go func() {
 		if err := h.Start(cfg.ServerPort); err != nil {
@@ -50,5 +70,8 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	cancel()
 	svc.StopMessageSender()
+	svc.StopCallbackDispatcher()
+	svc.StopLeaderElection()
 }
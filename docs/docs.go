@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/sent": {
+        "/blackout-dates": {
             "get": {
-                "description": "Retrieves a paginated list of sent messages",
+                "description": "Retrieves the blackout dates from today on, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,29 +25,94 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "schedule"
                 ],
-                "summary": "Get sent messages",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
+                "summary": "List blackout dates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BlackoutDateListResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a day on which no messages are sent, an existing date is replaced. Messages are deferred to the next day\nthe sending window opens, messages with bypass_quiet_hours are still sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Add blackout date",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Number of items per page (default: 10, max: 100)",
-                        "name": "page_size",
-                        "in": "query"
+                        "description": "Blackout date",
+                        "name": "blackout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BlackoutDateRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.PaginatedMessagesResponse"
+                            "$ref": "#/definitions/domain.BlackoutDate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/blackout-dates/{date}": {
+            "delete": {
+                "description": "Removes a date from the blackout calendar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Delete blackout date",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Date (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Removed"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -70,9 +135,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/start": {
+        "/content/analyze": {
             "post": {
-                "description": "Starts the automatic message sending process",
+                "description": "Returns the encoding (gsm7 or ucs2), character count and segment count of the content. A single segment holds\n160 GSM-7 or 70 UCS-2 characters, multipart segments 153 or 67. Turkish characters such as ş, ğ and ı force UCS-2.",
                 "consumes": [
                     "application/json"
                 ],
@@ -82,12 +147,82 @@ const docTemplate = `{
                 "tags": [
                     "message"
                 ],
-                "summary": "Start message sender",
+                "summary": "Analyze content",
+                "parameters": [
+                    {
+                        "description": "Content",
+                        "name": "content",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AnalyzeContentRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.StatusResponse"
+                            "$ref": "#/definitions/handler.AnalyzeContentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Server-Sent Events stream of message lifecycle events (created, sent, failed, dead) from every replica.\nReconnect with the Last-Event-ID header to replay the events missed from a bounded buffer.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream message lifecycle events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "sent,dead",
+                        "description": "Comma separated event types",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this message",
+                        "name": "message_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of this recipient",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LifecycleEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "500": {
@@ -99,9 +234,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/stop": {
+        "/inbound/{provider}": {
             "post": {
-                "description": "Stops the automatic message sending process",
+                "description": "Stores a reply forwarded by a provider, linked to the most recent message sent to its sender. Replies starting with\none of OPT_OUT_KEYWORDS add the sender to the suppression list. The provider authenticates with the bearer token configured in PROVIDER_TOKENS.\nA retried reply with the same messageId is stored once, the retry returns the stored reply.",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,14 +244,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "inbound"
+                ],
+                "summary": "Receive inbound reply",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the provider",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Reply",
+                        "name": "reply",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.InboundRequest"
+                        }
+                    }
                 ],
-                "summary": "Stop message sender",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/handler.StatusResponse"
+                            "$ref": "#/definitions/handler.InboundResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
                     "500": {
@@ -127,77 +299,2713 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "handler.MessageWithCache": {
-            "type": "object",
-            "properties": {
-                "cached_message_id": {
-                    "type": "string"
-                },
-                "cached_sent_at": {
-                    "type": "string"
-                },
-                "content": {
-                    "description": "Maximum 150 character (character limit is required for message content)",
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "is_sent": {
-                    "type": "boolean"
-                },
-                "sent_at": {
-                    "type": "string"
-                },
-                "to": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                }
-            }
         },
-        "handler.PaginatedMessagesResponse": {
-            "type": "object",
-            "properties": {
-                "messages": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/handler.MessageWithCache"
+        "/messages": {
+            "post": {
+                "description": "Creates a pending message. The content is either given or rendered from template_id, template_version (latest when empty), locale (DEFAULT_LOCALE when empty) and variables.\nWhen callback_url is set, a signed status event is posted to it once the message reaches a final state. It must point to a public address.\ntransliterate (TRANSLITERATE when empty) replaces characters outside GSM-7 using the locale's table, the response then has transliterated set and the original_content.\npriority picks the sending lane: critical always goes first, transactional and bulk share the rest of every batch by LANE_WEIGHTS.\nbypass_quiet_hours sends transactional messages such as OTPs outside the sending window, on blackout dates and in country quiet hours.\nWith an Idempotency-Key header a retried request returns the message created first with Idempotent-Replayed: true\ninstead of a duplicate. Keys are kept for IDEMPOTENCY_TTL and are unique per X-Client-ID, a key reused with a different body answers 422.\nX-Client-ID is required with an Idempotency-Key. It only keeps the keys of different callers apart and is not authenticated.\nWith DEDUP_WINDOW set, the same content to the same recipient within the window is created with status duplicate and duplicate_of, and is never sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Create message",
+                "parameters": [
+                    {
+                        "description": "Message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateMessageRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key of the request, retries must reuse it",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Calling client, scopes the idempotency keys, required with Idempotency-Key",
+                        "name": "X-Client-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Message"
+                        },
+                        "headers": {
+                            "Idempotent-Replayed": {
+                                "type": "string",
+                                "description": "true when the message was created by an earlier request with the same key"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     }
-                },
-                "page": {
-                    "type": "integer"
-                },
-                "page_size": {
-                    "type": "integer"
-                },
-                "total": {
-                    "type": "integer"
                 }
             }
         },
-        "handler.StatusResponse": {
-            "type": "object",
-            "properties": {
-                "status": {
-                    "type": "string"
-                }
-            }
+        "/messages/{id}": {
+            "get": {
+                "description": "Retrieves the full state of a message including attempts and provider ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels a pending message, sent or already cancelled messages cannot be cancelled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Cancel message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/callbacks": {
+            "get": {
+                "description": "Retrieves the status callbacks of a message with their delivery log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get message callbacks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.CallbackDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/events": {
+            "get": {
+                "description": "Retrieves the append only history of a message: status transitions, webhook attempts, delivery receipts and operator actions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get message history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MessageEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/resend": {
+            "post": {
+                "description": "Clones a sent message into a new pending message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Resend message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/messages/{id}/send": {
+            "post": {
+                "description": "Sends a single pending message synchronously and returns the webhook result. The country policy still applies,\na blocked destination or a message in quiet hours or over the country rate limit answers 409, as does any send while the sender is stopped with /stop.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Send a single message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Message ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SendMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/phone/parse": {
+            "post": {
+                "description": "Normalizes a phone number written in local or international format to E.164 and classifies it as mobile,\nlandline or unknown. Invalid numbers are rejected with the field and a code such as invalid_length.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "phone"
+                ],
+                "summary": "Parse phone number",
+                "parameters": [
+                    {
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ParsePhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/phone.Number"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/receipts/{provider}": {
+            "post": {
+                "description": "Records the delivery status reported by a provider for the messageId it returned. The provider authenticates with the bearer token configured in PROVIDER_TOKENS. Receipts for messages not marked sent yet are kept and applied once they are.\nReceipts may arrive out of order: the status only follows a receipt with a later timestamp, and a final status (delivered, undelivered, expired) is never replaced by enroute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "receipts"
+                ],
+                "summary": "Record delivery receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token of the provider",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Delivery receipt",
+                        "name": "receipt",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReceiptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReceiptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/replies/{phone}": {
+            "get": {
+                "description": "Retrieves a page of the replies received from a phone number, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "inbound"
+                ],
+                "summary": "Get replies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10, max: 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RepliesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sender/trigger": {
+            "post": {
+                "description": "Runs one sending cycle immediately without waiting for the ticker, serialized with the ticker. Answers 409 while the sender is stopped with /stop.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Trigger message sender",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TriggerResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sent": {
+            "get": {
+                "description": "Retrieves a paginated list of sent messages. Pass next_cursor or prev_cursor back as cursor for keyset pagination,\npage based pagination keeps working. Cursors require sorting by sent_at and the same filters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get sent messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1), ignored when cursor is set",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10, max: 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from next_cursor or prev_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "exact",
+                            "estimate",
+                            "none"
+                        ],
+                        "type": "string",
+                        "description": "Total count mode (default: exact for pages, none for cursors)",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact recipient",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient prefix, e.g. +90",
+                        "name": "to_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sent at or after (RFC3339)",
+                        "name": "sent_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sent before (RFC3339)",
+                        "name": "sent_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC3339)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC3339)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case insensitive content substring",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sent_at",
+                            "created_at",
+                            "to"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order (default: desc)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PaginatedMessagesResponse"
+                        },
+                        "headers": {
+                            "X-Degraded": {
+                                "type": "string",
+                                "description": "Set to cache-unavailable when the cached fields could not be loaded"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sent/export": {
+            "get": {
+                "description": "Streams every sent message matching the filters as csv or ndjson, ordered by sent_at.\nEvery row carries a cursor, pass the last received one as cursor to resume a broken download.\nThe response is gzip compressed when the client accepts it.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Export sent messages",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format (default: csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after the row with this cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact recipient",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Recipient prefix, e.g. +90",
+                        "name": "to_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sent at or after (RFC3339)",
+                        "name": "sent_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sent before (RFC3339)",
+                        "name": "sent_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC3339)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC3339)",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case insensitive content substring",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order (default: desc)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/start": {
+            "post": {
+                "description": "Starts the automatic message sending process on every replica, the state survives restarts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Start message sender",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "description": "Retrieves message counts by status, webhook attempt outcomes, success rate, time series, webhook latency percentiles and top failure reasons.\ncounts are the messages created in the range grouped by their current status, attempts count every webhook attempt.\nLatency percentiles are the upper bound of the histogram bucket they fall in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get delivery statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Range start (RFC3339, default: 24 hours before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Range end (RFC3339, default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "minute",
+                            "hour",
+                            "day"
+                        ],
+                        "type": "string",
+                        "description": "Time series interval (default: hour)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "provider",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "Retrieves the sender status of this replica, the fleet wide desired state, the current leader and the pending messages per lane",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get sender status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SenderStatusResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stop": {
+            "post": {
+                "description": "Stops the automatic message sending process on every replica, the state survives restarts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Stop message sender",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.StatusResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppressions": {
+            "get": {
+                "description": "Retrieves a page of the active suppression list entries, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "List suppressions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10, max: 100)",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuppressionListResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a recipient to the suppression list, an existing entry is replaced. Messages to suppressed recipients are never sent and end in the suppressed status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Add suppression",
+                "parameters": [
+                    {
+                        "description": "Suppression list entry",
+                        "name": "suppression",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuppressionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Suppression"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppressions/import": {
+            "post": {
+                "description": "Adds entries to the suppression list in bulk, existing entries are replaced. Accepts a JSON body, or text/csv\nwith the columns phone,reason,expires_at (RFC3339, optional) and an optional header row.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Import suppressions",
+                "parameters": [
+                    {
+                        "description": "Entries to import",
+                        "name": "entries",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuppressionImportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.SuppressionImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppressions/{phone}": {
+            "get": {
+                "description": "Retrieves the active suppression list entry of a phone number",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Get suppression",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Suppression"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a phone number from the suppression list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suppressions"
+                ],
+                "summary": "Delete suppression",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Phone number",
+                        "name": "phone",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Removed"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "description": "Retrieves the latest version of every template",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "List templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Template"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores the variants as the next version of the template, versions are immutable. Variant bodies use {{.Var}} placeholders and are keyed by locale (tr, en).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Create template version",
+                "parameters": [
+                    {
+                        "description": "Template",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "description": "Retrieves a version of a template, the latest when version is not set",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Get template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Template version",
+                        "name": "version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Template"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{id}/preview": {
+            "post": {
+                "description": "Renders a template variant with the given variables without creating a message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "templates"
+                ],
+                "summary": "Preview template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Version, locale and variables",
+                        "name": "preview",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PreviewTemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.PreviewTemplateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.BlackoutDate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2026-10-29"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Republic Day"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CallbackAttempt": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "attempted_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CallbackDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.LifecycleEventType"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "type": "integer"
+                },
+                "log": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CallbackAttempt"
+                    }
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.CallbackStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CallbackStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "CallbackStatusFailed": "Gave up after the maximum number of attempts"
+            },
+            "x-enum-varnames": [
+                "CallbackStatusPending",
+                "CallbackStatusDelivered",
+                "CallbackStatusFailed"
+            ]
+        },
+        "domain.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "enroute",
+                "delivered",
+                "undelivered",
+                "expired"
+            ],
+            "x-enum-comments": {
+                "DeliveryStatusEnroute": "Intermediate, the carrier is still trying to reach the handset",
+                "DeliveryStatusExpired": "Validity period passed before the handset was reachable"
+            },
+            "x-enum-varnames": [
+                "DeliveryStatusEnroute",
+                "DeliveryStatusDelivered",
+                "DeliveryStatusUndelivered",
+                "DeliveryStatusExpired"
+            ]
+        },
+        "domain.FailureReasonCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.InboundMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message_id": {
+                    "description": "Most recent outbound message to the sender of the reply",
+                    "type": "string"
+                },
+                "opt_out_keyword": {
+                    "description": "Set when the reply opted the sender out",
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "received_at": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LifecycleEvent": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Position in the replay buffer, assigned on publish",
+                    "type": "string"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.MessageStatus"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.LifecycleEventType"
+                }
+            }
+        },
+        "domain.LifecycleEventType": {
+            "type": "string",
+            "enum": [
+                "created",
+                "sent",
+                "failed",
+                "dead",
+                "suppressed",
+                "blocked",
+                "deferred",
+                "duplicate"
+            ],
+            "x-enum-comments": {
+                "LifecycleEventBlocked": "The destination country is not allowed, the message will not be sent",
+                "LifecycleEventDead": "The last attempt failed, the message will not be retried",
+                "LifecycleEventDeferred": "Quiet hours or the country rate limit postponed the message",
+                "LifecycleEventDuplicate": "The same content was sent to the recipient within the dedup window",
+                "LifecycleEventFailed": "An attempt failed, the message will be retried",
+                "LifecycleEventSuppressed": "The recipient is on the suppression list, the message will not be sent"
+            },
+            "x-enum-varnames": [
+                "LifecycleEventCreated",
+                "LifecycleEventSent",
+                "LifecycleEventFailed",
+                "LifecycleEventDead",
+                "LifecycleEventSuppressed",
+                "LifecycleEventBlocked",
+                "LifecycleEventDeferred",
+                "LifecycleEventDuplicate"
+            ]
+        },
+        "domain.Message": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "bypass_quiet_hours": {
+                    "description": "Transactional messages such as OTPs ignore sending windows, blackout dates and quiet hours",
+                    "type": "boolean"
+                },
+                "callback_url": {
+                    "description": "Receives signed status events when the message is sent or fails permanently",
+                    "type": "string"
+                },
+                "content": {
+                    "description": "Maximum 150 character (character limit is required for message content)",
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Destination region derived from the calling code of to",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_status": {
+                    "description": "Reported by the provider receipt, sent only means the webhook accepted it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DeliveryStatus"
+                        }
+                    ]
+                },
+                "duplicate_of": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "description": "gsm7 or ucs2",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_sent": {
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "not_before": {
+                    "description": "Deferred by the sending policy, the sender skips the message until then",
+                    "type": "string"
+                },
+                "original_content": {
+                    "description": "Content before transliteration, kept for audit",
+                    "type": "string"
+                },
+                "priority": {
+                    "$ref": "#/definitions/domain.Priority"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "segments": {
+                    "description": "Parts the carrier splits the content into",
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.MessageStatus"
+                },
+                "template_id": {
+                    "description": "Template the content was rendered from",
+                    "type": "string"
+                },
+                "template_version": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "transliterated": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MessageEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/domain.MessageStatus"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/domain.MessageEventKind"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "response_body": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/domain.MessageStatus"
+                }
+            }
+        },
+        "domain.MessageEventKind": {
+            "type": "string",
+            "enum": [
+                "transition",
+                "attempt",
+                "action",
+                "receipt",
+                "deferral"
+            ],
+            "x-enum-comments": {
+                "MessageEventAction": "Operator action through the api",
+                "MessageEventAttempt": "Webhook request, successful or not",
+                "MessageEventDeferral": "Sending postponed by the country policy",
+                "MessageEventReceipt": "Delivery receipt reported by the provider",
+                "MessageEventTransition": "Status change made by the service"
+            },
+            "x-enum-varnames": [
+                "MessageEventTransition",
+                "MessageEventAttempt",
+                "MessageEventAction",
+                "MessageEventReceipt",
+                "MessageEventDeferral"
+            ]
+        },
+        "domain.MessageStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "sent",
+                "failed",
+                "cancelled",
+                "suppressed",
+                "blocked",
+                "duplicate"
+            ],
+            "x-enum-comments": {
+                "MessageStatusBlocked": "Destination country is not allowed by the country policy, never sent",
+                "MessageStatusCancelled": "Cancelled by an operator before sending",
+                "MessageStatusDuplicate": "Same content to the same recipient within the dedup window, never sent",
+                "MessageStatusFailed": "Gave up after the maximum number of attempts",
+                "MessageStatusSuppressed": "Recipient is on the suppression list, never sent"
+            },
+            "x-enum-varnames": [
+                "MessageStatusPending",
+                "MessageStatusSent",
+                "MessageStatusFailed",
+                "MessageStatusCancelled",
+                "MessageStatusSuppressed",
+                "MessageStatusBlocked",
+                "MessageStatusDuplicate"
+            ]
+        },
+        "domain.Priority": {
+            "type": "string",
+            "enum": [
+                "critical",
+                "transactional",
+                "bulk"
+            ],
+            "x-enum-comments": {
+                "PriorityBulk": "Marketing, shares the batch with transactional by weight",
+                "PriorityCritical": "Always fills the batch first"
+            },
+            "x-enum-varnames": [
+                "PriorityCritical",
+                "PriorityTransactional",
+                "PriorityBulk"
+            ]
+        },
+        "domain.SenderState": {
+            "type": "string",
+            "enum": [
+                "running",
+                "stopped"
+            ],
+            "x-enum-varnames": [
+                "SenderStateRunning",
+                "SenderStateStopped"
+            ]
+        },
+        "domain.StatsBucket": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "dead": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "sent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Suppression": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "Never expires when empty",
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/domain.SuppressionSource"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SuppressionSource": {
+            "type": "string",
+            "enum": [
+                "inbound",
+                "api",
+                "import"
+            ],
+            "x-enum-varnames": [
+                "SuppressionSourceInbound",
+                "SuppressionSourceAPI",
+                "SuppressionSourceImport"
+            ]
+        },
+        "domain.Template": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "variants": {
+                    "description": "Body per locale with {{.Var}} placeholders",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.AnalyzeContentRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Üçüncü örnek mesaj"
+                },
+                "locale": {
+                    "description": "Transliteration language, DEFAULT_LOCALE when empty",
+                    "type": "string",
+                    "example": "tr"
+                }
+            }
+        },
+        "handler.AnalyzeContentResponse": {
+            "type": "object",
+            "properties": {
+                "characters": {
+                    "description": "Characters as the user sees them",
+                    "type": "integer"
+                },
+                "encoding": {
+                    "$ref": "#/definitions/sms.Encoding"
+                },
+                "non_gsm_chars": {
+                    "description": "Characters that force UCS-2",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "per_segment": {
+                    "description": "Capacity of a segment in units",
+                    "type": "integer"
+                },
+                "segments": {
+                    "description": "Parts the carrier splits the message into",
+                    "type": "integer"
+                },
+                "transliterated": {
+                    "description": "Set when transliteration changes the content",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sms.Analysis"
+                        }
+                    ]
+                },
+                "units": {
+                    "description": "Septets for GSM-7, UTF-16 code units for UCS-2",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.BlackoutDateListResponse": {
+            "type": "object",
+            "properties": {
+                "blackout_dates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BlackoutDate"
+                    }
+                }
+            }
+        },
+        "handler.BlackoutDateRequest": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD in SENDING_TIMEZONE",
+                    "type": "string",
+                    "example": "2026-10-29"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Republic Day"
+                }
+            }
+        },
+        "handler.CreateMessageRequest": {
+            "type": "object",
+            "properties": {
+                "bypass_quiet_hours": {
+                    "description": "Transactional messages such as OTPs",
+                    "type": "boolean",
+                    "example": false
+                },
+                "callback_url": {
+                    "type": "string",
+                    "example": "https://example.com/sms-status"
+                },
+                "content": {
+                    "type": "string",
+                    "example": "Merhaba!"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "tr"
+                },
+                "priority": {
+                    "description": "critical, transactional or bulk, DEFAULT_PRIORITY when empty",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Priority"
+                        }
+                    ],
+                    "example": "critical"
+                },
+                "template_id": {
+                    "type": "string",
+                    "example": "otp"
+                },
+                "template_version": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "to": {
+                    "type": "string",
+                    "example": "+905071773757"
+                },
+                "transliterate": {
+                    "description": "Overrides TRANSLITERATE for this message",
+                    "type": "boolean",
+                    "example": true
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.CreateTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Login verification code"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "otp"
+                },
+                "variants": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Machine readable reason, set for validation errors",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "field": {
+                    "description": "Invalid request field, set for validation errors",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.InboundRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "STOP"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "+905551111111"
+                },
+                "messageId": {
+                    "type": "string",
+                    "example": "2b1c57c4-8d1e-4f0e-9c57-6e3d8f1b2a90"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2025-06-01T12:00:00Z"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "INSIDER"
+                }
+            }
+        },
+        "handler.InboundResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "opted_out": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.MessageWithCache": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "bypass_quiet_hours": {
+                    "description": "Transactional messages such as OTPs ignore sending windows, blackout dates and quiet hours",
+                    "type": "boolean"
+                },
+                "cached_message_id": {
+                    "type": "string"
+                },
+                "cached_sent_at": {
+                    "type": "string"
+                },
+                "callback_url": {
+                    "description": "Receives signed status events when the message is sent or fails permanently",
+                    "type": "string"
+                },
+                "content": {
+                    "description": "Maximum 150 character (character limit is required for message content)",
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Destination region derived from the calling code of to",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_status": {
+                    "description": "Reported by the provider receipt, sent only means the webhook accepted it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DeliveryStatus"
+                        }
+                    ]
+                },
+                "duplicate_of": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "description": "gsm7 or ucs2",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_sent": {
+                    "type": "boolean"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "not_before": {
+                    "description": "Deferred by the sending policy, the sender skips the message until then",
+                    "type": "string"
+                },
+                "original_content": {
+                    "description": "Content before transliteration, kept for audit",
+                    "type": "string"
+                },
+                "priority": {
+                    "$ref": "#/definitions/domain.Priority"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "segments": {
+                    "description": "Parts the carrier splits the content into",
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.MessageStatus"
+                },
+                "template_id": {
+                    "description": "Template the content was rendered from",
+                    "type": "string"
+                },
+                "template_version": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "transliterated": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.PaginatedMessagesResponse": {
+            "type": "object",
+            "properties": {
+                "degraded": {
+                    "description": "Cached fields are missing because redis is unavailable",
+                    "type": "boolean"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.MessageWithCache"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_estimated": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.ParsePhoneRequest": {
+            "type": "object",
+            "properties": {
+                "phone": {
+                    "type": "string",
+                    "example": "0507 177 37 57"
+                },
+                "region": {
+                    "description": "Region of numbers without a country code, DEFAULT_REGION when empty",
+                    "type": "string",
+                    "example": "TR"
+                }
+            }
+        },
+        "handler.PreviewTemplateRequest": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "example": "tr"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "handler.PreviewTemplateResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "encoding": {
+                    "type": "string"
+                },
+                "fits": {
+                    "description": "Whether a message can be created with the content",
+                    "type": "boolean"
+                },
+                "length": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "max_length": {
+                    "type": "integer"
+                },
+                "segments": {
+                    "type": "integer"
+                },
+                "template_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.ReceiptRequest": {
+            "type": "object",
+            "properties": {
+                "messageId": {
+                    "type": "string",
+                    "example": "67f2f8a8-ea58-4ed0-a6f9-ff217df4d849"
+                },
+                "status": {
+                    "enum": [
+                        "enroute",
+                        "delivered",
+                        "undelivered",
+                        "expired"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DeliveryStatus"
+                        }
+                    ],
+                    "example": "delivered"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2025-06-01T12:00:00Z"
+                }
+            }
+        },
+        "handler.ReceiptResponse": {
+            "type": "object",
+            "properties": {
+                "matched": {
+                    "description": "False when the receipt arrived before the message was marked sent, it is applied later",
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.RepliesResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "replies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.InboundMessage"
+                    }
+                }
+            }
+        },
+        "handler.SendMessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "$ref": "#/definitions/domain.Message"
+                },
+                "webhook_message": {
+                    "type": "string"
+                },
+                "webhook_message_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SenderStatusResponse": {
+            "type": "object",
+            "properties": {
+                "desired_state": {
+                    "$ref": "#/definitions/domain.SenderState"
+                },
+                "instance_id": {
+                    "type": "string"
+                },
+                "is_leader": {
+                    "type": "boolean"
+                },
+                "leader": {
+                    "type": "string"
+                },
+                "leader_election_enabled": {
+                    "type": "boolean"
+                },
+                "queue_depth": {
+                    "description": "Pending messages per lane, deferred messages included",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "running": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.StatsAttempts": {
+            "type": "object",
+            "properties": {
+                "dead": {
+                    "description": "Failed attempts after which the message will not be retried anymore",
+                    "type": "integer"
+                },
+                "failed": {
+                    "description": "Failed attempts, including the ones that were retried later",
+                    "type": "integer"
+                },
+                "sent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.StatsLatency": {
+            "type": "object",
+            "properties": {
+                "avg": {
+                    "type": "number"
+                },
+                "p50": {
+                    "type": "integer"
+                },
+                "p90": {
+                    "type": "integer"
+                },
+                "p95": {
+                    "type": "integer"
+                },
+                "p99": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "$ref": "#/definitions/handler.StatsAttempts"
+                },
+                "counts": {
+                    "description": "Messages created in the range by status",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "$ref": "#/definitions/handler.StatsLatency"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.StatsBucket"
+                    }
+                },
+                "success_rate": {
+                    "description": "Share of the attempts accepted by the webhook",
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "top_failure_reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FailureReasonCount"
+                    }
+                }
+            }
+        },
+        "handler.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SuppressionImportRequest": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.SuppressionRequest"
+                    }
+                }
+            }
+        },
+        "handler.SuppressionImportResponse": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.SuppressionListResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "suppressions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Suppression"
+                    }
+                }
+            }
+        },
+        "handler.SuppressionRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-01T00:00:00Z"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+905551111111"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Unsubscribed by customer support"
+                }
+            }
+        },
+        "handler.TriggerResponse": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "description": "Destination country not allowed by the country policy",
+                    "type": "integer"
+                },
+                "deferred": {
+                    "description": "Postponed by quiet hours or the country rate limit",
+                    "type": "integer"
+                },
+                "duplicates": {
+                    "description": "Same content already sent to the recipient within the dedup window",
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "sent": {
+                    "type": "integer"
+                },
+                "suppressed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "phone.Number": {
+            "type": "object",
+            "properties": {
+                "calling_code": {
+                    "description": "90",
+                    "type": "string"
+                },
+                "e164": {
+                    "description": "+905551234567",
+                    "type": "string"
+                },
+                "national_number": {
+                    "description": "5551234567",
+                    "type": "string"
+                },
+                "region": {
+                    "description": "TR",
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/phone.Type"
+                }
+            }
+        },
+        "phone.Type": {
+            "type": "string",
+            "enum": [
+                "mobile",
+                "landline",
+                "unknown"
+            ],
+            "x-enum-comments": {
+                "TypeUnknown": "Valid number outside the known mobile and landline ranges"
+            },
+            "x-enum-varnames": [
+                "TypeMobile",
+                "TypeLandline",
+                "TypeUnknown"
+            ]
+        },
+        "sms.Analysis": {
+            "type": "object",
+            "properties": {
+                "characters": {
+                    "description": "Characters as the user sees them",
+                    "type": "integer"
+                },
+                "encoding": {
+                    "$ref": "#/definitions/sms.Encoding"
+                },
+                "non_gsm_chars": {
+                    "description": "Characters that force UCS-2",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "per_segment": {
+                    "description": "Capacity of a segment in units",
+                    "type": "integer"
+                },
+                "segments": {
+                    "description": "Parts the carrier splits the message into",
+                    "type": "integer"
+                },
+                "units": {
+                    "description": "Septets for GSM-7, UTF-16 code units for UCS-2",
+                    "type": "integer"
+                }
+            }
+        },
+        "sms.Encoding": {
+            "type": "string",
+            "enum": [
+                "gsm7",
+                "ucs2"
+            ],
+            "x-enum-comments": {
+                "EncodingGSM7": "GSM 03.38 default alphabet, 7 bits per character",
+                "EncodingUCS2": "UTF-16, used as soon as one character is outside GSM-7"
+            },
+            "x-enum-varnames": [
+                "EncodingGSM7",
+                "EncodingUCS2"
+            ]
         }
     }
 }`
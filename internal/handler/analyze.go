@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"insider-challenge/pkg/sms"
+)
+
+// AnalyzeContentRequest represents the content to analyze
+type AnalyzeContentRequest struct {
+	Content string `json:"content" example:"Üçüncü örnek mesaj"`
+	Locale  string `json:"locale,omitempty" example:"tr"` // Transliteration language, DEFAULT_LOCALE when empty
+}
+
+// AnalyzeContentResponse represents how the content is encoded and split, and how it would be after transliteration
+type AnalyzeContentResponse struct {
+	sms.Analysis
+	Transliterated *sms.Analysis `json:"transliterated,omitempty"` // Set when transliteration changes the content
+}
+
+// @Summary Analyze content
+// @Description Returns the encoding (gsm7 or ucs2), character count and segment count of the content. A single segment holds
+// @Description 160 GSM-7 or 70 UCS-2 characters, multipart segments 153 or 67. Turkish characters such as ş, ğ and ı force UCS-2.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param content body AnalyzeContentRequest true "Content"
+// @Success 200 {object} AnalyzeContentResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /content/analyze [post]
+func (h *Handler) handleAnalyzeContent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req AnalyzeContentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	response := AnalyzeContentResponse{Analysis: h.service.AnalyzeContent(req.Content)}
+	locale := req.Locale
+	if locale == "" {
+		locale = h.cfg.DefaultLocale
+	}
+	if transliterated := sms.Transliterate(req.Content, locale); transliterated != req.Content {
+		analysis := h.service.AnalyzeContent(transliterated)
+		response.Transliterated = &analysis
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// BlackoutDateRequest represents a blackout calendar entry
+type BlackoutDateRequest struct {
+	Date        string `json:"date" example:"2026-10-29"` // YYYY-MM-DD in SENDING_TIMEZONE
+	Description string `json:"description,omitempty" example:"Republic Day"`
+}
+
+// BlackoutDateListResponse represents the upcoming blackout dates
+type BlackoutDateListResponse struct {
+	BlackoutDates []domain.BlackoutDate `json:"blackout_dates"`
+}
+
+// handleBlackoutDates dispatches the blackout calendar list and add routes
+func (h *Handler) handleBlackoutDates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListBlackoutDates(w, r)
+	case http.MethodPost:
+		h.handleAddBlackoutDate(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// @Summary List blackout dates
+// @Description Retrieves the blackout dates from today on, oldest first
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Success 200 {object} BlackoutDateListResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /blackout-dates [get]
+func (h *Handler) handleListBlackoutDates(w http.ResponseWriter, r *http.Request) {
+	blackouts, err := h.service.ListBlackoutDates(r.Context())
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BlackoutDateListResponse{BlackoutDates: blackouts})
+}
+
+// @Summary Add blackout date
+// @Description Adds a day on which no messages are sent, an existing date is replaced. Messages are deferred to the next day
+// @Description the sending window opens, messages with bypass_quiet_hours are still sent.
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param blackout body BlackoutDateRequest true "Blackout date"
+// @Success 201 {object} domain.BlackoutDate
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /blackout-dates [post]
+func (h *Handler) handleAddBlackoutDate(w http.ResponseWriter, r *http.Request) {
+	var req BlackoutDateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	blackout, err := h.service.AddBlackoutDate(r.Context(), service.BlackoutInput(req))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, blackout)
+}
+
+// @Summary Delete blackout date
+// @Description Removes a date from the blackout calendar
+// @Tags schedule
+// @Accept json
+// @Produce json
+// @Param date path string true "Date (YYYY-MM-DD)"
+// @Success 204 "Removed"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /blackout-dates/{date} [delete]
+func (h *Handler) handleDeleteBlackoutDate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if err := h.service.DeleteBlackoutDate(r.Context(), r.PathValue("date")); err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
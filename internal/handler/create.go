@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// maxRequestBodySize upper bound of json request bodies
+const maxRequestBodySize = 1 << 20
+
+// Idempotency request and response headers
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	ClientIDHeader           = "X-Client-ID"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// CreateMessageRequest represents a new message
+type CreateMessageRequest struct {
+	To          string `json:"to" example:"+905071773757"`
+	Content     string `json:"content,omitempty" example:"Merhaba!"`
+	CallbackURL string `json:"callback_url,omitempty" example:"https://example.com/sms-status"`
+
+	TemplateID      string            `json:"template_id,omitempty" example:"otp"`
+	TemplateVersion int               `json:"template_version,omitempty" example:"2"`
+	Locale          string            `json:"locale,omitempty" example:"tr"`
+	Variables       map[string]string `json:"variables,omitempty"`
+
+	Transliterate *bool `json:"transliterate,omitempty" example:"true"` // Overrides TRANSLITERATE for this message
+
+	BypassQuietHours bool            `json:"bypass_quiet_hours,omitempty" example:"false"` // Transactional messages such as OTPs
+	Priority         domain.Priority `json:"priority,omitempty" example:"critical"`        // critical, transactional or bulk, DEFAULT_PRIORITY when empty
+}
+
+// @Summary Create message
+// @Description Creates a pending message. The content is either given or rendered from template_id, template_version (latest when empty), locale (DEFAULT_LOCALE when empty) and variables.
+// @Description When callback_url is set, a signed status event is posted to it once the message reaches a final state. It must point to a public address.
+// @Description transliterate (TRANSLITERATE when empty) replaces characters outside GSM-7 using the locale's table, the response then has transliterated set and the original_content.
+// @Description priority picks the sending lane: critical always goes first, transactional and bulk share the rest of every batch by LANE_WEIGHTS.
+// @Description bypass_quiet_hours sends transactional messages such as OTPs outside the sending window, on blackout dates and in country quiet hours.
+// @Description With an Idempotency-Key header a retried request returns the message created first with Idempotent-Replayed: true
+// @Description instead of a duplicate. Keys are kept for IDEMPOTENCY_TTL and are unique per X-Client-ID, a key reused with a different body answers 422.
+// @Description X-Client-ID is required with an Idempotency-Key. It only keeps the keys of different callers apart and is not authenticated.
+// @Description With DEDUP_WINDOW set, the same content to the same recipient within the window is created with status duplicate and duplicate_of, and is never sent.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param message body CreateMessageRequest true "Message"
+// @Param Idempotency-Key header string false "Unique key of the request, retries must reuse it"
+// @Param X-Client-ID header string false "Calling client, scopes the idempotency keys, required with Idempotency-Key"
+// @Success 201 {object} domain.Message
+// @Header 201 {string} Idempotent-Replayed "true when the message was created by an earlier request with the same key"
+// @Failure 400 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages [post]
+func (h *Handler) handleCreateMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req CreateMessageRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	input := service.NewMessage{
+		To:          req.To,
+		Content:     req.Content,
+		CallbackURL: req.CallbackURL,
+
+		TemplateID:      req.TemplateID,
+		TemplateVersion: req.TemplateVersion,
+		Locale:          req.Locale,
+		Variables:       req.Variables,
+
+		Transliterate: req.Transliterate,
+
+		BypassQuietHours: req.BypassQuietHours,
+		Priority:         req.Priority,
+	}
+
+	key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+	if key == "" {
+		msg, err := h.service.CreateMessage(r.Context(), input)
+		if err != nil {
+			writeAppError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, msg)
+		return
+	}
+
+	msg, replayed, err := h.service.CreateMessageIdempotent(r.Context(), input, service.Idempotency{
+		ClientID: strings.TrimSpace(r.Header.Get(ClientIDHeader)),
+		Key:      key,
+	})
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	if replayed {
+		w.Header().Set(IdempotentReplayedHeader, "true")
+	}
+
+	writeJSON(w, http.StatusCreated, msg)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"insider-challenge/internal/repository"
+	"insider-challenge/internal/service"
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+)
+
+// idempotencyRepository holds the keys of earlier requests, other calls panic on the nil interface
+type idempotencyRepository struct {
+	repository.Repository
+
+	keys map[string]*domain.IdempotencyKey
+}
+
+func (r *idempotencyRepository) GetIdempotencyKey(ctx context.Context, clientID, key string) (*domain.IdempotencyKey, error) {
+	return r.keys[clientID+"/"+key], nil
+}
+
+func TestCreateMessageIdempotency(t *testing.T) {
+	repo := &idempotencyRepository{keys: map[string]*domain.IdempotencyKey{
+		"shop/order-1": {ClientID: "shop", Key: "order-1", RequestHash: "hash of another request"},
+	}}
+	cfg := &config.Config{DefaultRegion: "TR"}
+	h := New(service.New(repo, cfg), cfg)
+
+	tests := []struct {
+		name      string
+		body      string
+		headers   map[string]string
+		status    int
+		wantField string
+	}{
+		{
+			name:    "key reused with another body",
+			body:    `{"to":"+905551234567","content":"Hello"}`,
+			headers: map[string]string{IdempotencyKeyHeader: "order-1", ClientIDHeader: "shop"},
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "key reused with an invalid body",
+			body:    `{"to":"not a number"}`,
+			headers: map[string]string{IdempotencyKeyHeader: "order-1", ClientIDHeader: "shop"},
+			status:  http.StatusUnprocessableEntity,
+		},
+		{
+			name:      "key without client id",
+			body:      `{"to":"+905551234567","content":"Hello"}`,
+			headers:   map[string]string{IdempotencyKeyHeader: "order-1"},
+			status:    http.StatusBadRequest,
+			wantField: ClientIDHeader,
+		},
+		{
+			name:    "malformed body",
+			body:    `{"to":`,
+			headers: map[string]string{IdempotencyKeyHeader: "order-1", ClientIDHeader: "shop"},
+			status:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(tt.body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			h.mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("POST /messages status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Field != tt.wantField {
+				t.Errorf("POST /messages field = %q, want %q", resp.Field, tt.wantField)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+// cursorPayload is the serialized form of an opaque pagination cursor
+type cursorPayload struct {
+	SentAt   time.Time `json:"s"`
+	ID       uuid.UUID `json:"i"`
+	Backward bool      `json:"b,omitempty"`
+	SortAsc  bool      `json:"a,omitempty"`
+}
+
+// errInvalidCursor is returned for cursors that were not issued by this api
+var errInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor builds an opaque cursor positioned at the message
+func encodeCursor(msg domain.Message, backward, sortAsc bool) string {
+	if msg.SentAt == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(cursorPayload{
+		SentAt:   *msg.SentAt,
+		ID:       msg.ID,
+		Backward: backward,
+		SortAsc:  sortAsc,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor
+func decodeCursor(value string) (domain.MessageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return domain.MessageCursor{}, errInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.ID == uuid.Nil || payload.SentAt.IsZero() {
+		return domain.MessageCursor{}, errInvalidCursor
+	}
+
+	return domain.MessageCursor{
+		SentAt:   payload.SentAt,
+		ID:       payload.ID,
+		Backward: payload.Backward,
+		SortAsc:  payload.SortAsc,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sentAt := time.Date(2025, 6, 1, 12, 30, 15, 123456789, time.UTC)
+	msg := domain.Message{ID: uuid.New(), SentAt: &sentAt}
+
+	for _, backward := range []bool{false, true} {
+		for _, sortAsc := range []bool{false, true} {
+			cursor, err := decodeCursor(encodeCursor(msg, backward, sortAsc))
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+			want := domain.MessageCursor{SentAt: sentAt, ID: msg.ID, Backward: backward, SortAsc: sortAsc}
+			// Nanoseconds have to survive, the keyset compares against the stored sent_at
+			if !cursor.SentAt.Equal(want.SentAt) || cursor.ID != want.ID || cursor.Backward != backward || cursor.SortAsc != sortAsc {
+				t.Errorf("decodeCursor(encodeCursor()) = %+v, want %+v", cursor, want)
+			}
+		}
+	}
+}
+
+func TestEncodeCursorUnsentMessage(t *testing.T) {
+	if got := encodeCursor(domain.Message{ID: uuid.New()}, false, false); got != "" {
+		t.Errorf("encodeCursor() = %q for an unsent message, want no cursor", got)
+	}
+}
+
+func TestDecodeCursorRejectsForeignValues(t *testing.T) {
+	encode := func(json string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "empty", value: ""},
+		{name: "not base64", value: "not a cursor!"},
+		{name: "padded base64", value: base64.URLEncoding.EncodeToString([]byte(`{"s":"2025-06-01T12:00:00Z"}`))},
+		{name: "not json", value: encode("plain text")},
+		{name: "missing id", value: encode(`{"s":"2025-06-01T12:00:00Z"}`)},
+		{name: "missing sent at", value: encode(`{"i":"` + uuid.NewString() + `"}`)},
+		{name: "malformed id", value: encode(`{"s":"2025-06-01T12:00:00Z","i":"42"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.value); err != errInvalidCursor {
+				t.Errorf("decodeCursor(%q) error = %v, want %v", tt.value, err, errInvalidCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// eventsHeartbeatInterval keeps idle streams alive through proxies
+const eventsHeartbeatInterval = 15 * time.Second
+
+// eventFilter selects the events a stream client is interested in
+type eventFilter struct {
+	types     map[domain.LifecycleEventType]bool
+	messageID string
+	to        string
+}
+
+// matches reports whether the event passes the filter
+func (f eventFilter) matches(event domain.LifecycleEvent) bool {
+	if len(f.types) > 0 && !f.types[event.Type] {
+		return false
+	}
+	if f.messageID != "" && event.MessageID.String() != f.messageID {
+		return false
+	}
+	if f.to != "" && event.To != f.to {
+		return false
+	}
+	return true
+}
+
+// @Summary Stream message lifecycle events
+// @Description Server-Sent Events stream of message lifecycle events (created, sent, failed, dead) from every replica.
+// @Description Reconnect with the Last-Event-ID header to replay the events missed from a bounded buffer.
+// @Tags events
+// @Produce text/event-stream
+// @Param type query string false "Comma separated event types" example(sent,dead)
+// @Param message_id query string false "Only events of this message"
+// @Param to query string false "Only events of this recipient"
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Success 200 {object} domain.LifecycleEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /events [get]
+func (h *Handler) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := eventFilter{
+		types:     make(map[domain.LifecycleEventType]bool),
+		messageID: query.Get("message_id"),
+		to:        query.Get("to"),
+	}
+	if types := query.Get("type"); types != "" {
+		for _, eventType := range strings.Split(types, ",") {
+			filter.types[domain.LifecycleEventType(strings.TrimSpace(eventType))] = true
+		}
+	}
+
+	// Subscribe before replaying so nothing published in between is lost
+	sub := h.service.SubscribeEvents()
+	defer h.service.UnsubscribeEvents(sub)
+
+	lastID := r.Header.Get("Last-Event-ID")
+	var replay []domain.LifecycleEvent
+	if lastID != "" {
+		var err error
+		replay, err = h.service.ReplayEvents(r.Context(), lastID)
+		if err != nil {
+			writeAppError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, event := range replay {
+		if filter.matches(event) {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		lastID = event.ID
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind, the client reconnects with Last-Event-ID
+				return
+			}
+			// Skip what the replay already delivered
+			if lastID != "" && !service.EventIDAfter(event.ID, lastID) {
+				continue
+			}
+			if !filter.matches(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeEvent writes a single event in the Server-Sent Events format
+func writeEvent(w http.ResponseWriter, event domain.LifecycleEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
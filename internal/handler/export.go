@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+// Export formats
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+// exportFlushEvery number of rows written between flushes to the client
+const exportFlushEvery = 500
+
+// exportCSVHeader columns of the csv export, cursor resumes the export after that row
+var exportCSVHeader = []string{"id", "to", "content", "status", "provider", "provider_message_id", "attempts", "sent_at", "created_at", "cursor"}
+
+// ExportedMessage represents a single ndjson export line
+type ExportedMessage struct {
+	domain.Message
+	Cursor string `json:"cursor"`
+}
+
+// @Summary Export sent messages
+// @Description Streams every sent message matching the filters as csv or ndjson, ordered by sent_at.
+// @Description Every row carries a cursor, pass the last received one as cursor to resume a broken download.
+// @Description The response is gzip compressed when the client accepts it.
+// @Tags message
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format (default: csv)" Enums(csv, ndjson)
+// @Param cursor query string false "Resume after the row with this cursor"
+// @Param to query string false "Exact recipient"
+// @Param to_prefix query string false "Recipient prefix, e.g. +90"
+// @Param sent_after query string false "Sent at or after (RFC3339)"
+// @Param sent_before query string false "Sent before (RFC3339)"
+// @Param created_after query string false "Created at or after (RFC3339)"
+// @Param created_before query string false "Created before (RFC3339)"
+// @Param provider query string false "Provider name"
+// @Param q query string false "Case insensitive content substring"
+// @Param order query string false "Sort order (default: desc)" Enums(asc, desc)
+// @Success 200 {string} string
+// @Failure 400 {object} ErrorResponse
+// @Router /sent/export [get]
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	filter, err := parseMessageFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.SortBy != domain.SortBySentAt {
+		writeError(w, http.StatusBadRequest, "exports are always sorted by sent_at")
+		return
+	}
+
+	var after *domain.MessageCursor
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		cursor, err := decodeCursor(cursorStr)
+		if err != nil || cursor.Backward {
+			writeError(w, http.StatusBadRequest, errInvalidCursor.Error())
+			return
+		}
+		if cursor.SortAsc != filter.SortAsc {
+			writeError(w, http.StatusBadRequest, "cursor was issued for a different sort order")
+			return
+		}
+		after = &cursor
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatCSV
+	}
+
+	var contentType string
+	switch format {
+	case exportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		writeError(w, http.StatusBadRequest, "invalid export format: "+format)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="sent-messages.`+format+`"`)
+
+	var out io.Writer = w
+	var gz *gzip.Writer
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		gz = gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+
+	rows := newExportWriter(out, format, filter.SortAsc)
+	flusher, _ := w.(http.Flusher)
+
+	// Once streaming started the status code is sent, errors can only end the stream early
+	written := 0
+	err = h.service.ExportSentMessages(r.Context(), filter, after, func(msg domain.Message) error {
+		if err := rows.write(msg); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := rows.flush(); err != nil {
+				return err
+			}
+			if gz != nil {
+				if err := gz.Flush(); err != nil {
+					return err
+				}
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Export of sent messages stopped after %d rows: %v", written, err)
+	}
+
+	if err := rows.flush(); err != nil {
+		log.Printf("Failed to flush export: %v", err)
+	}
+}
+
+// exportWriter encodes exported rows in the requested format
+type exportWriter struct {
+	format  string
+	sortAsc bool
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+// newExportWriter creates a new export writer
+func newExportWriter(out io.Writer, format string, sortAsc bool) *exportWriter {
+	ew := &exportWriter{format: format, sortAsc: sortAsc}
+	if format == exportFormatCSV {
+		ew.csv = csv.NewWriter(out)
+	} else {
+		ew.json = json.NewEncoder(out)
+	}
+	return ew
+}
+
+// write encodes a single message with its resume cursor
+func (ew *exportWriter) write(msg domain.Message) error {
+	cursor := encodeCursor(msg, false, ew.sortAsc)
+
+	if ew.json != nil {
+		return ew.json.Encode(ExportedMessage{Message: msg, Cursor: cursor})
+	}
+
+	if !ew.started {
+		ew.started = true
+		if err := ew.csv.Write(exportCSVHeader); err != nil {
+			return err
+		}
+	}
+
+	var sentAt string
+	if msg.SentAt != nil {
+		sentAt = msg.SentAt.Format(time.RFC3339Nano)
+	}
+
+	return ew.csv.Write([]string{
+		msg.ID.String(),
+		msg.To,
+		msg.Content,
+		string(msg.Status),
+		msg.Provider,
+		msg.ProviderMessageID,
+		strconv.Itoa(msg.Attempts),
+		sentAt,
+		msg.CreatedAt.Format(time.RFC3339Nano),
+		cursor,
+	})
+}
+
+// flush writes buffered csv rows, the json encoder does not buffer
+func (ew *exportWriter) flush() error {
+	if ew.csv == nil {
+		return nil
+	}
+	if !ew.started {
+		ew.started = true
+		if err := ew.csv.Write(exportCSVHeader); err != nil {
+			return err
+		}
+	}
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
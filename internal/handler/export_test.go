@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+func exportedMessages() []domain.Message {
+	sentAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	return []domain.Message{
+		{ID: uuid.New(), To: "+905551234567", Content: "Hello, \"world\"\nsecond line", Status: domain.MessageStatusSent, SentAt: &sentAt, Attempts: 1},
+		{ID: uuid.New(), To: "+905551234568", Content: "Bye", Status: domain.MessageStatusSent, SentAt: &sentAt, Attempts: 2},
+	}
+}
+
+func TestExportWriterCSV(t *testing.T) {
+	var out bytes.Buffer
+	rows := newExportWriter(&out, exportFormatCSV, true)
+	messages := exportedMessages()
+	for _, msg := range messages {
+		if err := rows.write(msg); err != nil {
+			t.Fatalf("write() error = %v", err)
+		}
+	}
+	if err := rows.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	// Content with quotes and newlines has to survive the round trip
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("exported csv does not parse: %v", err)
+	}
+	if len(records) != len(messages)+1 || strings.Join(records[0], ",") != strings.Join(exportCSVHeader, ",") {
+		t.Fatalf("exported csv = %q, want the header and %d rows", records, len(messages))
+	}
+	for i, msg := range messages {
+		record := records[i+1]
+		if record[0] != msg.ID.String() || record[2] != msg.Content {
+			t.Errorf("row %d = %q, want message %s", i, record, msg.ID)
+		}
+
+		cursor, err := decodeCursor(record[len(record)-1])
+		if err != nil || cursor.ID != msg.ID || !cursor.SortAsc || cursor.Backward {
+			t.Errorf("row %d cursor = %+v, %v, want a forward ascending cursor at the message", i, cursor, err)
+		}
+	}
+}
+
+func TestExportWriterEmptyCSV(t *testing.T) {
+	var out bytes.Buffer
+	if err := newExportWriter(&out, exportFormatCSV, false).flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+	if got, want := out.String(), strings.Join(exportCSVHeader, ",")+"\n"; got != want {
+		t.Errorf("empty export = %q, want only the header %q", got, want)
+	}
+}
+
+func TestExportWriterNDJSON(t *testing.T) {
+	var out bytes.Buffer
+	rows := newExportWriter(&out, exportFormatNDJSON, false)
+	messages := exportedMessages()
+	for _, msg := range messages {
+		if err := rows.write(msg); err != nil {
+			t.Fatalf("write() error = %v", err)
+		}
+	}
+	if err := rows.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(messages) {
+		t.Fatalf("exported %d lines, want %d", len(lines), len(messages))
+	}
+	for i, line := range lines {
+		var exported ExportedMessage
+		if err := json.Unmarshal([]byte(line), &exported); err != nil {
+			t.Fatalf("line %d does not parse: %v", i, err)
+		}
+		if exported.ID != messages[i].ID || exported.Content != messages[i].Content || exported.Cursor == "" {
+			t.Errorf("line %d = %s, want message %s with a cursor", i, line, messages[i].ID)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
 	"insider-challenge/internal/service"
@@ -33,6 +34,36 @@ func New(service *service.Service, cfg *config.Config) *Handler {
 	h.mux.HandleFunc("/start", h.handleStart)
 	h.mux.HandleFunc("/stop", h.handleStop)
 	h.mux.HandleFunc("/sent", h.handleSent)
+	h.mux.HandleFunc("/sent/export", h.handleExport)
+	h.mux.HandleFunc("/status", h.handleStatus)
+	h.mux.HandleFunc("/stats", h.handleStats)
+	h.mux.HandleFunc("/events", h.handleEvents)
+	h.mux.HandleFunc("/sender/trigger", h.handleTrigger)
+	h.mux.HandleFunc("/replies/{phone}", h.handleReplies)
+	h.mux.HandleFunc("/suppressions", h.handleSuppressions)
+	h.mux.HandleFunc("/suppressions/import", h.handleImportSuppressions)
+	h.mux.HandleFunc("/suppressions/{phone}", h.handleSuppression)
+	h.mux.HandleFunc("/templates", h.handleTemplates)
+	h.mux.HandleFunc("/templates/{id}", h.handleTemplate)
+	h.mux.HandleFunc("/templates/{id}/preview", h.handlePreviewTemplate)
+	h.mux.HandleFunc("/content/analyze", h.handleAnalyzeContent)
+	h.mux.HandleFunc("/phone/parse", h.handleParsePhone)
+	h.mux.HandleFunc("/blackout-dates", h.handleBlackoutDates)
+	h.mux.HandleFunc("/blackout-dates/{date}", h.handleDeleteBlackoutDate)
+	h.mux.HandleFunc("/messages", h.handleCreateMessage)
+	h.mux.HandleFunc("/messages/{id}", h.handleMessage)
+	h.mux.HandleFunc("/messages/{id}/callbacks", h.handleMessageCallbacks)
+	h.mux.HandleFunc("/messages/{id}/events", h.handleMessageEvents)
+	h.mux.HandleFunc("/messages/{id}/send", h.handleSendMessage)
+	h.mux.HandleFunc("/messages/{id}/resend", h.handleResendMessage)
+
+	// Provider callbacks are only served once a provider can authenticate
+	if len(cfg.ProviderTokens) > 0 {
+		h.mux.HandleFunc("/receipts/{provider}", h.handleReceipt)
+		h.mux.HandleFunc("/inbound/{provider}", h.handleInbound)
+	} else {
+		log.Println("PROVIDER_TOKENS is empty, /receipts and /inbound are disabled")
+	}
 
 	return h
 }
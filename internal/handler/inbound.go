@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// InboundRequest represents a reply forwarded by a provider
+type InboundRequest struct {
+	From      string     `json:"from" example:"+905551111111"`
+	To        string     `json:"to,omitempty" example:"INSIDER"`
+	Content   string     `json:"content" example:"STOP"`
+	MessageID string     `json:"messageId,omitempty" example:"2b1c57c4-8d1e-4f0e-9c57-6e3d8f1b2a90"`
+	Timestamp *time.Time `json:"timestamp,omitempty" example:"2025-06-01T12:00:00Z"`
+}
+
+// InboundResponse represents the result of receiving a reply
+type InboundResponse struct {
+	ID       string `json:"id"`
+	OptedOut bool   `json:"opted_out"`
+}
+
+// RepliesResponse represents a page of the replies received from a phone number
+type RepliesResponse struct {
+	Replies  []domain.InboundMessage `json:"replies"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	HasMore  bool                    `json:"has_more"`
+}
+
+// @Summary Receive inbound reply
+// @Description Stores a reply forwarded by a provider, linked to the most recent message sent to its sender. Replies starting with
+// @Description one of OPT_OUT_KEYWORDS add the sender to the suppression list. The provider authenticates with the bearer token configured in PROVIDER_TOKENS.
+// @Description A retried reply with the same messageId is stored once, the retry returns the stored reply.
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param Authorization header string true "Bearer token of the provider"
+// @Param reply body InboundRequest true "Reply"
+// @Success 202 {object} InboundResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /inbound/{provider} [post]
+func (h *Handler) handleInbound(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req InboundRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	input := service.InboundInput{
+		From:              req.From,
+		To:                req.To,
+		Content:           req.Content,
+		ProviderMessageID: req.MessageID,
+	}
+	if req.Timestamp != nil {
+		input.ReceivedAt = *req.Timestamp
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	inbound, err := h.service.ReceiveInbound(r.Context(), r.PathValue("provider"), token, input)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, InboundResponse{
+		ID:       inbound.ID.String(),
+		OptedOut: inbound.OptOutKeyword != "",
+	})
+}
+
+// @Summary Get replies
+// @Description Retrieves a page of the replies received from a phone number, newest first
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param phone path string true "Phone number"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} RepliesResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /replies/{phone} [get]
+func (h *Handler) handleReplies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	page, pageSize := h.parsePagination(r.URL.Query())
+	replies, hasMore, err := h.service.GetReplies(r.Context(), r.PathValue("phone"), page, pageSize)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, RepliesResponse{
+		Replies:  replies,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  hasMore,
+	})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// handleMessage dispatches the message detail and cancel routes
+func (h *Handler) handleMessage(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetMessage(w, r)
+	case http.MethodDelete:
+		h.handleCancelMessage(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// @Summary Get message
+// @Description Retrieves the full state of a message including attempts and provider ID
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} domain.Message
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id} [get]
+func (h *Handler) handleGetMessage(w http.ResponseWriter, r *http.Request) {
+	msg, err := h.service.GetMessage(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// @Summary Cancel message
+// @Description Cancels a pending message, sent or already cancelled messages cannot be cancelled
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} domain.Message
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id} [delete]
+func (h *Handler) handleCancelMessage(w http.ResponseWriter, r *http.Request) {
+	msg, err := h.service.CancelMessage(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, msg)
+}
+
+// @Summary Resend message
+// @Description Clones a sent message into a new pending message
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 201 {object} domain.Message
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id}/resend [post]
+func (h *Handler) handleResendMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	msg, err := h.service.ResendMessage(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, msg)
+}
+
+// @Summary Get message callbacks
+// @Description Retrieves the status callbacks of a message with their delivery log
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {array} domain.CallbackDelivery
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id}/callbacks [get]
+func (h *Handler) handleMessageCallbacks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	deliveries, err := h.service.GetMessageCallbacks(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// @Summary Get message history
+// @Description Retrieves the append only history of a message: status transitions, webhook attempts, delivery receipts and operator actions
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {array} domain.MessageEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id}/events [get]
+func (h *Handler) handleMessageEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	events, err := h.service.GetMessageEvents(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ParsePhoneRequest represents the phone number to parse
+type ParsePhoneRequest struct {
+	Phone  string `json:"phone" example:"0507 177 37 57"`
+	Region string `json:"region,omitempty" example:"TR"` // Region of numbers without a country code, DEFAULT_REGION when empty
+}
+
+// @Summary Parse phone number
+// @Description Normalizes a phone number written in local or international format to E.164 and classifies it as mobile,
+// @Description landline or unknown. Invalid numbers are rejected with the field and a code such as invalid_length.
+// @Tags phone
+// @Accept json
+// @Produce json
+// @Param phone body ParsePhoneRequest true "Phone number"
+// @Success 200 {object} phone.Number
+// @Failure 400 {object} ErrorResponse
+// @Router /phone/parse [post]
+func (h *Handler) handleParsePhone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ParsePhoneRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	number, err := h.service.ParsePhone(req.Phone, req.Region)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, number)
+}
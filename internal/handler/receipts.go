@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// ReceiptRequest represents a delivery receipt sent by a provider
+type ReceiptRequest struct {
+	MessageID string                `json:"messageId" example:"67f2f8a8-ea58-4ed0-a6f9-ff217df4d849"`
+	Status    domain.DeliveryStatus `json:"status" example:"delivered" enums:"enroute,delivered,undelivered,expired"`
+	Timestamp *time.Time            `json:"timestamp,omitempty" example:"2025-06-01T12:00:00Z"`
+}
+
+// ReceiptResponse represents the result of recording a delivery receipt
+type ReceiptResponse struct {
+	Status  string `json:"status"`
+	Matched bool   `json:"matched"` // False when the receipt arrived before the message was marked sent, it is applied later
+}
+
+// @Summary Record delivery receipt
+// @Description Records the delivery status reported by a provider for the messageId it returned. The provider authenticates with the bearer token configured in PROVIDER_TOKENS. Receipts for messages not marked sent yet are kept and applied once they are.
+// @Description Receipts may arrive out of order: the status only follows a receipt with a later timestamp, and a final status (delivered, undelivered, expired) is never replaced by enroute.
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param Authorization header string true "Bearer token of the provider"
+// @Param receipt body ReceiptRequest true "Delivery receipt"
+// @Success 202 {object} ReceiptResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /receipts/{provider} [post]
+func (h *Handler) handleReceipt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ReceiptRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	input := service.ReceiptInput{
+		ProviderMessageID: req.MessageID,
+		Status:            req.Status,
+	}
+	if req.Timestamp != nil {
+		input.OccurredAt = *req.Timestamp
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	_, matched, err := h.service.RecordReceipt(r.Context(), r.PathValue("provider"), token, input)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, ReceiptResponse{Status: "accepted", Matched: matched})
+}
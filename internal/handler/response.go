@@ -1,5 +1,13 @@
 package handler
 
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	apperrors "insider-challenge/pkg/errors"
+)
+
 // StatusResponse represents a simple status response
 type StatusResponse struct {
 	Status string `json:"status"`
@@ -8,4 +16,72 @@ type StatusResponse struct {
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Field string `json:"field,omitempty"` // Invalid request field, set for validation errors
+	Code  string `json:"code,omitempty"`  // Machine readable reason, set for validation errors
+}
+
+// writeJSON writes the value as a json response with the given status code
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response with the given status code
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, ErrorResponse{Error: message})
+}
+
+// writeAppError maps application errors to their http status codes
+func writeAppError(w http.ResponseWriter, err error) {
+	var validationErr *apperrors.ValidationError
+	if errors.As(err, &validationErr) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: validationErr.Error(),
+			Field: validationErr.Field,
+			Code:  validationErr.Code,
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, apperrors.ErrInvalidRequest):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+	case errors.Is(err, apperrors.ErrMessageNotFound):
+		writeError(w, http.StatusNotFound, "Message not found")
+	case errors.Is(err, apperrors.ErrSuppressionNotFound):
+		writeError(w, http.StatusNotFound, "Suppression not found")
+	case errors.Is(err, apperrors.ErrTemplateNotFound):
+		writeError(w, http.StatusNotFound, "Template not found")
+	case errors.Is(err, apperrors.ErrBlackoutDateNotFound):
+		writeError(w, http.StatusNotFound, "Blackout date not found")
+	case errors.Is(err, apperrors.ErrRecipientSuppressed):
+		writeError(w, http.StatusConflict, "Recipient is on the suppression list")
+	case errors.Is(err, apperrors.ErrIdempotencyKeyReused):
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+	case errors.Is(err, apperrors.ErrDuplicateMessage):
+		writeError(w, http.StatusConflict, "Same content was already sent to the recipient")
+	case errors.Is(err, apperrors.ErrDestinationBlocked):
+		writeError(w, http.StatusConflict, "Destination country is blocked")
+	case errors.Is(err, apperrors.ErrMessageDeferred):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, apperrors.ErrDatabaseOperation):
+		writeError(w, http.StatusInternalServerError, "Database operation failed")
+	case errors.Is(err, apperrors.ErrMessageNotPending):
+		writeError(w, http.StatusConflict, "Message is not pending")
+	case errors.Is(err, apperrors.ErrMessageNotSent):
+		writeError(w, http.StatusConflict, "Message is not sent")
+	case errors.Is(err, apperrors.ErrSenderStopped):
+		writeError(w, http.StatusConflict, "Message sender is stopped, start it with /start first")
+	case errors.Is(err, apperrors.ErrNotLeader):
+		writeError(w, http.StatusConflict, "This replica is not the sender leader, retry against the leader shown in /status")
+	case errors.Is(err, apperrors.ErrWebhookFailed):
+		writeError(w, http.StatusBadGateway, err.Error())
+	case errors.Is(err, apperrors.ErrStateStore):
+		writeError(w, http.StatusServiceUnavailable, "State store unavailable")
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
 }
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+// SendMessageResponse represents the result of sending a single message
+type SendMessageResponse struct {
+	Message        domain.Message `json:"message"`
+	WebhookMessage string         `json:"webhook_message"`
+	WebhookID      string         `json:"webhook_message_id"`
+}
+
+// @Summary Send a single message
+// @Description Sends a single pending message synchronously and returns the webhook result. The country policy still applies,
+// @Description a blocked destination or a message in quiet hours or over the country rate limit answers 409, as does any send while the sender is stopped with /stop.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} SendMessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /messages/{id}/send [post]
+func (h *Handler) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	msg, response, err := h.service.SendMessageNow(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SendMessageResponse{
+		Message:        *msg,
+		WebhookMessage: response.Message,
+		WebhookID:      response.MessageID,
+	})
+}
@@ -2,16 +2,15 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"insider-challenge/pkg/config"
 	domain "insider-challenge/pkg/domain"
-	apperrors "insider-challenge/pkg/errors"
 )
 
 // MessageWithCache extends the Message struct with Redis cache inf
@@ -23,43 +22,162 @@ type MessageWithCache struct {
 
 // PaginatedMessagesResponse represents paginated response of messages with cache inf
 type PaginatedMessagesResponse struct {
-	Messages []MessageWithCache `json:"messages"`
-	Page     int                `json:"page"`
-	PageSize int                `json:"page_size"`
-	Total    int64              `json:"total"`
+	Messages       []MessageWithCache `json:"messages"`
+	Page           int                `json:"page,omitempty"`
+	PageSize       int                `json:"page_size"`
+	Total          *int64             `json:"total,omitempty"`
+	TotalEstimated bool               `json:"total_estimated,omitempty"`
+	NextCursor     string             `json:"next_cursor,omitempty"`
+	PrevCursor     string             `json:"prev_cursor,omitempty"`
+	Degraded       bool               `json:"degraded,omitempty"` // Cached fields are missing because redis is unavailable
 }
 
+// cacheLookupTimeout upper bound of the redis lookup while serving a listing
+const cacheLookupTimeout = 500 * time.Millisecond
+
+// degradedHeader is set when the response was served without some of its data
+const degradedHeader = "X-Degraded"
+
+// Total modes of the sent listing
+const (
+	totalExact    = "exact"
+	totalEstimate = "estimate"
+	totalNone     = "none"
+)
+
 // @Summary Get sent messages
-// @Description Retrieves a paginated list of sent messages
+// @Description Retrieves a paginated list of sent messages. Pass next_cursor or prev_cursor back as cursor for keyset pagination,
+// @Description page based pagination keeps working. Cursors require sorting by sent_at and the same filters.
 // @Tags message
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number (default: 1)"
+// @Param page query int false "Page number (default: 1), ignored when cursor is set"
 // @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Param cursor query string false "Opaque cursor from next_cursor or prev_cursor"
+// @Param total query string false "Total count mode (default: exact for pages, none for cursors)" Enums(exact, estimate, none)
+// @Param to query string false "Exact recipient"
+// @Param to_prefix query string false "Recipient prefix, e.g. +90"
+// @Param sent_after query string false "Sent at or after (RFC3339)"
+// @Param sent_before query string false "Sent before (RFC3339)"
+// @Param created_after query string false "Created at or after (RFC3339)"
+// @Param created_before query string false "Created before (RFC3339)"
+// @Param provider query string false "Provider name"
+// @Param q query string false "Case insensitive content substring"
+// @Param sort query string false "Sort field" Enums(sent_at, created_at, to)
+// @Param order query string false "Sort order (default: desc)" Enums(asc, desc)
 // @Success 200 {object} PaginatedMessagesResponse
+// @Header 200 {string} X-Degraded "Set to cache-unavailable when the cached fields could not be loaded"
 // @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /sent [get]
 func (h *Handler) handleSent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		response := ErrorResponse{Error: "Method not allowed"}
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(response)
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	query := r.URL.Query()
+
 	// Parse pagination param
+	page, pageSize := h.parsePagination(query)
+
+	filter, err := parseMessageFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var messages []domain.Message
+	var hasNext, hasPrev bool
+	totalMode := totalExact
+
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := decodeCursor(cursorStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var hasMore bool
+		messages, hasMore, err = h.service.GetSentMessagesByCursor(r.Context(), filter, cursor, pageSize)
+		if err != nil {
+			writeAppError(w, err)
+			return
+		}
+
+		// The page the cursor came from is always on the opposite side
+		if cursor.Backward {
+			hasNext, hasPrev = true, hasMore
+		} else {
+			hasNext, hasPrev = hasMore, true
+		}
+		page = 0
+		totalMode = totalNone
+	} else {
+		var hasMore bool
+		messages, hasMore, err = h.service.GetSentMessages(r.Context(), filter, page, pageSize)
+		if err != nil {
+			writeAppError(w, err)
+			return
+		}
+		hasNext, hasPrev = hasMore, page > 1
+	}
+
+	messagesWithCache, degraded := h.withCache(r.Context(), messages)
+
+	response := PaginatedMessagesResponse{
+		Messages: messagesWithCache,
+		Page:     page,
+		PageSize: pageSize,
+		Degraded: degraded,
+	}
+
+	if mode := query.Get("total"); mode != "" {
+		totalMode = mode
+	}
+	switch totalMode {
+	case totalExact, totalEstimate:
+		total, err := h.service.CountSentMessages(r.Context(), filter, totalMode == totalEstimate)
+		if err != nil {
+			writeAppError(w, err)
+			return
+		}
+		response.Total = &total
+		response.TotalEstimated = totalMode == totalEstimate
+	case totalNone:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid total mode: "+totalMode)
+		return
+	}
+
+	// Keyset cursors are only available for the sent_at order
+	if filter.SortBy == domain.SortBySentAt && len(messages) > 0 {
+		if hasNext {
+			response.NextCursor = encodeCursor(messages[len(messages)-1], false, filter.SortAsc)
+		}
+		if hasPrev {
+			response.PrevCursor = encodeCursor(messages[0], true, filter.SortAsc)
+		}
+	}
+
+	if degraded {
+		w.Header().Set(degradedHeader, "cache-unavailable")
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parsePagination reads page and page_size, invalid values fall back to the defaults
+func (h *Handler) parsePagination(query url.Values) (int, int) {
 	page := 1
 	pageSize := h.cfg.DefaultPageSize
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+	if pageStr := query.Get("page"); pageStr != "" {
 		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
 			page = p
 		}
 	}
 
-	if pageSizeStr := r.URL.Query().Get("page_size"); pageSizeStr != "" {
+	if pageSizeStr := query.Get("page_size"); pageSizeStr != "" {
 		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
 			if ps > h.cfg.MaxPageSize {
 				ps = h.cfg.MaxPageSize
@@ -68,30 +186,24 @@ func (h *Handler) handleSent(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	messages, total, err := h.service.GetSentMessages(page, pageSize)
-	if err != nil {
-		var appErr *apperrors.AppError
-		if errors.As(err, &appErr) {
-			switch appErr.Unwrap() {
-			case apperrors.ErrMessageNotFound:
-				w.WriteHeader(http.StatusNotFound)
-				json.NewEncoder(w).Encode(ErrorResponse{Error: "No messages found"})
-			case apperrors.ErrDatabaseOperation:
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(ErrorResponse{Error: "Database operation failed"})
-			default:
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Error getting sent messages: %v", err)})
-			}
-			return
-		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Error getting sent messages: %v", err)})
-		return
+	return page, pageSize
+}
+
+// withCache adds the redis cache information to the messages, degraded is true when redis could not be reached
+func (h *Handler) withCache(ctx context.Context, messages []domain.Message) ([]MessageWithCache, bool) {
+	// Bound redis by the request so a hanging redis cannot hang the listing
+	ctx, cancel := context.WithTimeout(ctx, cacheLookupTimeout)
+	defer cancel()
+
+	messageIDs := make([]string, len(messages))
+	for i, msg := range messages {
+		messageIDs[i] = msg.ID.String()
 	}
 
-	// Create a context for redis operations
-	ctx := context.Background()
+	caches, err := config.GetMessageCaches(ctx, messageIDs)
+	if err != nil {
+		log.Printf("Failed to get message caches, serving without them: %v", err)
+	}
 
 	// Convert messages to include redis cache inf
 	messagesWithCache := make([]MessageWithCache, len(messages))
@@ -100,26 +212,60 @@ func (h *Handler) handleSent(w http.ResponseWriter, r *http.Request) {
 			Message: msg,
 		}
 
-		// Get cached information from redis
-		if cache, err := config.GetMessageCache(ctx, msg.ID.String()); err == nil {
+		if cache, ok := caches[msg.ID.String()]; ok {
 			sentAt := time.Unix(cache.SentAt, 0)
 			messagesWithCache[i].CachedSentAt = &sentAt
 			messagesWithCache[i].CachedMessageID = cache.MessageID
 		}
 	}
 
-	response := PaginatedMessagesResponse{
-		Messages: messagesWithCache,
-		Page:     page,
-		PageSize: pageSize,
-		Total:    total,
+	return messagesWithCache, err != nil
+}
+
+// parseMessageFilter parses the listing filters and sort fields from the query string
+func parseMessageFilter(r *http.Request) (domain.MessageFilter, error) {
+	query := r.URL.Query()
+
+	filter := domain.MessageFilter{
+		To:              query.Get("to"),
+		ToPrefix:        query.Get("to_prefix"),
+		Provider:        query.Get("provider"),
+		ContentContains: query.Get("q"),
+		SortBy:          domain.SortBySentAt,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		response := ErrorResponse{Error: fmt.Sprintf("Error encoding response: %v", err)}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
-		return
+	timeParams := map[string]**time.Time{
+		"sent_after":     &filter.SentAfter,
+		"sent_before":    &filter.SentBefore,
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
 	}
+	for name, target := range timeParams {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return domain.MessageFilter{}, fmt.Errorf("invalid %s, expected RFC3339 time: %s", name, value)
+		}
+		*target = &t
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if !domain.IsValidSortField(sortBy) {
+			return domain.MessageFilter{}, fmt.Errorf("invalid sort field: %s", sortBy)
+		}
+		filter.SortBy = sortBy
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "desc":
+	case "asc":
+		filter.SortAsc = true
+	default:
+		return domain.MessageFilter{}, fmt.Errorf("invalid sort order: %s", order)
+	}
+
+	return filter, nil
 }
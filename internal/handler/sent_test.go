@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+)
+
+func TestParseMessageFilter(t *testing.T) {
+	sentAfter := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.MessageFilter
+		wantErr bool
+	}{
+		{name: "defaults", query: "", want: domain.MessageFilter{SortBy: domain.SortBySentAt}},
+		{
+			name:  "filters",
+			query: "to_prefix=%2B90&provider=webhook&q=code&sent_after=2025-06-01T12:00:00Z",
+			want:  domain.MessageFilter{ToPrefix: "+90", Provider: "webhook", ContentContains: "code", SentAfter: &sentAfter, SortBy: domain.SortBySentAt},
+		},
+		{name: "ascending", query: "order=asc", want: domain.MessageFilter{SortBy: domain.SortBySentAt, SortAsc: true}},
+		{name: "explicit descending", query: "order=desc", want: domain.MessageFilter{SortBy: domain.SortBySentAt}},
+		{name: "unknown sort field", query: "sort=content", wantErr: true},
+		{name: "unknown order", query: "order=random", wantErr: true},
+		{name: "time without zone", query: "created_before=2025-06-01T12:00:00", wantErr: true},
+		{name: "date only", query: "sent_before=2025-06-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMessageFilter(httptest.NewRequest("GET", "/sent?"+tt.query, nil))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseMessageFilter() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMessageFilter() error = %v", err)
+			}
+
+			sentAfterEqual := (got.SentAfter == nil) == (tt.want.SentAfter == nil) &&
+				(got.SentAfter == nil || got.SentAfter.Equal(*tt.want.SentAfter))
+			got.SentAfter, tt.want.SentAfter = nil, nil
+			if !sentAfterEqual || got != tt.want {
+				t.Errorf("parseMessageFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithCacheDegradesWithoutRedis(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+
+	h := &Handler{}
+	messages := []domain.Message{{ID: uuid.New()}, {ID: uuid.New()}}
+
+	got, degraded := h.withCache(context.Background(), messages)
+	if !degraded {
+		t.Error("withCache() without redis is not degraded")
+	}
+	if len(got) != len(messages) {
+		t.Fatalf("withCache() = %d messages, want all %d", len(got), len(messages))
+	}
+	for i, msg := range got {
+		if msg.ID != messages[i].ID || msg.CachedSentAt != nil || msg.CachedMessageID != "" {
+			t.Errorf("withCache()[%d] = %+v, want message %s without cache", i, msg, messages[i].ID)
+		}
+	}
+
+	// An empty page never asks redis
+	if _, degraded := h.withCache(context.Background(), nil); degraded {
+		t.Error("withCache() of an empty page is degraded")
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+// defaultStatsRange time range of the statistics when from is not given
+const defaultStatsRange = 24 * time.Hour
+
+// StatsAttempts represents the webhook attempt outcomes of the time range, a message retried three times counts three times
+type StatsAttempts struct {
+	Sent   int64 `json:"sent"`
+	Failed int64 `json:"failed"` // Failed attempts, including the ones that were retried later
+	Dead   int64 `json:"dead"`   // Failed attempts after which the message will not be retried anymore
+}
+
+// StatsLatency represents the webhook latency in milliseconds
+type StatsLatency struct {
+	Avg float64 `json:"avg"`
+	P50 int     `json:"p50"`
+	P90 int     `json:"p90"`
+	P95 int     `json:"p95"`
+	P99 int     `json:"p99"`
+}
+
+// StatsResponse represents the delivery statistics
+type StatsResponse struct {
+	From              time.Time                      `json:"from"`
+	To                time.Time                      `json:"to"`
+	Interval          string                         `json:"interval"`
+	Provider          string                         `json:"provider,omitempty"`
+	Counts            map[domain.MessageStatus]int64 `json:"counts"` // Messages created in the range by status
+	Attempts          StatsAttempts                  `json:"attempts"`
+	SuccessRate       float64                        `json:"success_rate"` // Share of the attempts accepted by the webhook
+	LatencyMs         StatsLatency                   `json:"latency_ms"`
+	Series            []domain.StatsBucket           `json:"series"`
+	TopFailureReasons []domain.FailureReasonCount    `json:"top_failure_reasons"`
+}
+
+// @Summary Get delivery statistics
+// @Description Retrieves message counts by status, webhook attempt outcomes, success rate, time series, webhook latency percentiles and top failure reasons.
+// @Description counts are the messages created in the range grouped by their current status, attempts count every webhook attempt.
+// @Description Latency percentiles are the upper bound of the histogram bucket they fall in.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param from query string false "Range start (RFC3339, default: 24 hours before to)"
+// @Param to query string false "Range end (RFC3339, default: now)"
+// @Param interval query string false "Time series interval (default: hour)" Enums(minute, hour, day)
+// @Param provider query string false "Provider name"
+// @Success 200 {object} StatsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /stats [get]
+func (h *Handler) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+
+	filter := domain.StatsFilter{
+		To:       time.Now().UTC(),
+		Provider: query.Get("provider"),
+		Interval: query.Get("interval"),
+	}
+	if filter.Interval == "" {
+		filter.Interval = "hour"
+	}
+
+	if to := query.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid to, expected RFC3339 time: "+to)
+			return
+		}
+		filter.To = t
+	}
+
+	filter.From = filter.To.Add(-defaultStatsRange)
+	if from := query.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from, expected RFC3339 time: "+from)
+			return
+		}
+		filter.From = t
+	}
+
+	stats, err := h.service.GetStats(r.Context(), filter)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	series := stats.Series
+	if series == nil {
+		series = []domain.StatsBucket{}
+	}
+	failures := stats.TopFailures
+	if failures == nil {
+		failures = []domain.FailureReasonCount{}
+	}
+
+	writeJSON(w, http.StatusOK, StatsResponse{
+		From:     filter.From,
+		To:       filter.To,
+		Interval: filter.Interval,
+		Provider: filter.Provider,
+		Counts:   stats.Statuses,
+		Attempts: StatsAttempts{
+			Sent:   stats.Sent,
+			Failed: stats.Failed,
+			Dead:   stats.Dead,
+		},
+		SuccessRate: roundRate(stats.SuccessRate),
+		LatencyMs: StatsLatency{
+			Avg: roundRate(stats.AvgLatency),
+			P50: stats.Latency[50],
+			P90: stats.Latency[90],
+			P95: stats.Latency[95],
+			P99: stats.Latency[99],
+		},
+		Series:            series,
+		TopFailureReasons: failures,
+	})
+}
+
+// roundRate rounds to four decimals for readable responses
+func roundRate(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
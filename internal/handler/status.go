@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+// SenderStatusResponse represents the sender status of the replica that served the request
+type SenderStatusResponse struct {
+	InstanceID            string                    `json:"instance_id"`
+	Running               bool                      `json:"running"`
+	DesiredState          domain.SenderState        `json:"desired_state"`
+	LeaderElectionEnabled bool                      `json:"leader_election_enabled"`
+	Leader                string                    `json:"leader,omitempty"`
+	IsLeader              bool                      `json:"is_leader"`
+	QueueDepth            map[domain.Priority]int64 `json:"queue_depth"` // Pending messages per lane, deferred messages included
+}
+
+// @Summary Get sender status
+// @Description Retrieves the sender status of this replica, the fleet wide desired state, the current leader and the pending messages per lane
+// @Tags message
+// @Accept json
+// @Produce json
+// @Success 200 {object} SenderStatusResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /status [get]
+func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	status, err := h.service.GetSenderStatus(r.Context())
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SenderStatusResponse{
+		InstanceID:            status.InstanceID,
+		Running:               status.Running,
+		DesiredState:          status.DesiredState,
+		LeaderElectionEnabled: status.LeaderElectionEnabled,
+		Leader:                status.Leader,
+		IsLeader:              status.IsLeader,
+		QueueDepth:            status.QueueDepth,
+	})
+}
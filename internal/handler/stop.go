@@ -1,13 +1,13 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/http"
+
+	domain "insider-challenge/pkg/domain"
 )
 
 // @Summary Stop message sender
-// @Description Stops the automatic message sending process
+// @Description Stops the automatic message sending process on every replica, the state survives restarts
 // @Tags message
 // @Accept json
 // @Produce json
@@ -16,29 +16,21 @@ import (
 // @Router /stop [post]
 func (h *Handler) handleStop(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		response := ErrorResponse{Error: "Method not allowed"}
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(response)
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	if !h.service.IsRunning() {
-		response := StatusResponse{Status: "Message sender is not running"}
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
+	wasRunning := h.service.IsRunning()
+
+	if err := h.service.SetSenderState(r.Context(), domain.SenderStateStopped); err != nil {
+		writeAppError(w, err)
 		return
 	}
 
-	h.service.StopMessageSender()
-
-	response := StatusResponse{Status: "Message sender stopped"}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		response := ErrorResponse{Error: fmt.Sprintf("Error encoding response: %v", err)}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(response)
+	if !wasRunning {
+		writeJSON(w, http.StatusOK, StatusResponse{Status: "Message sender is not running"})
 		return
 	}
+
+	writeJSON(w, http.StatusOK, StatusResponse{Status: "Message sender stopped"})
 }
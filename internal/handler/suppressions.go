@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// maxImportBodySize upper bound of suppression import bodies
+const maxImportBodySize = 32 << 20
+
+// SuppressionRequest represents a suppression list entry
+type SuppressionRequest struct {
+	Phone     string     `json:"phone" example:"+905551111111"`
+	Reason    string     `json:"reason,omitempty" example:"Unsubscribed by customer support"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-01T00:00:00Z"`
+}
+
+// SuppressionImportRequest represents a bulk import of suppression list entries
+type SuppressionImportRequest struct {
+	Entries []SuppressionRequest `json:"entries"`
+}
+
+// SuppressionImportResponse represents the result of a bulk import
+type SuppressionImportResponse struct {
+	Imported int `json:"imported"`
+}
+
+// SuppressionListResponse represents a page of the suppression list
+type SuppressionListResponse struct {
+	Suppressions []domain.Suppression `json:"suppressions"`
+	Page         int                  `json:"page"`
+	PageSize     int                  `json:"page_size"`
+	HasMore      bool                 `json:"has_more"`
+}
+
+// handleSuppressions dispatches the suppression list and add routes
+func (h *Handler) handleSuppressions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListSuppressions(w, r)
+	case http.MethodPost:
+		h.handleAddSuppression(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// @Summary List suppressions
+// @Description Retrieves a page of the active suppression list entries, newest first
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} SuppressionListResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /suppressions [get]
+func (h *Handler) handleListSuppressions(w http.ResponseWriter, r *http.Request) {
+	page, pageSize := h.parsePagination(r.URL.Query())
+	suppressions, hasMore, err := h.service.ListSuppressions(r.Context(), page, pageSize)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuppressionListResponse{
+		Suppressions: suppressions,
+		Page:         page,
+		PageSize:     pageSize,
+		HasMore:      hasMore,
+	})
+}
+
+// @Summary Add suppression
+// @Description Adds a recipient to the suppression list, an existing entry is replaced. Messages to suppressed recipients are never sent and end in the suppressed status.
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param suppression body SuppressionRequest true "Suppression list entry"
+// @Success 201 {object} domain.Suppression
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /suppressions [post]
+func (h *Handler) handleAddSuppression(w http.ResponseWriter, r *http.Request) {
+	var req SuppressionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	suppression, err := h.service.AddSuppression(r.Context(), service.SuppressionInput(req))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, suppression)
+}
+
+// handleSuppression dispatches the suppression detail and delete routes
+func (h *Handler) handleSuppression(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetSuppression(w, r)
+	case http.MethodDelete:
+		h.handleDeleteSuppression(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// @Summary Get suppression
+// @Description Retrieves the active suppression list entry of a phone number
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param phone path string true "Phone number"
+// @Success 200 {object} domain.Suppression
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /suppressions/{phone} [get]
+func (h *Handler) handleGetSuppression(w http.ResponseWriter, r *http.Request) {
+	suppression, err := h.service.GetSuppression(r.Context(), r.PathValue("phone"))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, suppression)
+}
+
+// @Summary Delete suppression
+// @Description Removes a phone number from the suppression list
+// @Tags suppressions
+// @Accept json
+// @Produce json
+// @Param phone path string true "Phone number"
+// @Success 204 "Removed"
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /suppressions/{phone} [delete]
+func (h *Handler) handleDeleteSuppression(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSuppression(r.Context(), r.PathValue("phone")); err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Import suppressions
+// @Description Adds entries to the suppression list in bulk, existing entries are replaced. Accepts a JSON body, or text/csv
+// @Description with the columns phone,reason,expires_at (RFC3339, optional) and an optional header row.
+// @Tags suppressions
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param entries body SuppressionImportRequest true "Entries to import"
+// @Success 200 {object} SuppressionImportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /suppressions/import [post]
+func (h *Handler) handleImportSuppressions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	var inputs []service.SuppressionInput
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		var err error
+		if inputs, err = parseSuppressionCSV(body); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	} else {
+		var req SuppressionImportRequest
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		for _, entry := range req.Entries {
+			inputs = append(inputs, service.SuppressionInput(entry))
+		}
+	}
+
+	imported, err := h.service.ImportSuppressions(r.Context(), inputs)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, SuppressionImportResponse{Imported: imported})
+}
+
+// parseSuppressionCSV reads phone,reason,expires_at rows, a first row starting with "phone" is a header
+func parseSuppressionCSV(r io.Reader) ([]service.SuppressionInput, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var inputs []service.SuppressionInput
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return inputs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %v", err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "phone") {
+			continue
+		}
+		if len(record) > 3 {
+			return nil, fmt.Errorf("line %d: expected at most 3 columns", line)
+		}
+
+		input := service.SuppressionInput{Phone: record[0]}
+		if len(record) > 1 {
+			input.Reason = record[1]
+		}
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			expiresAt, err := time.Parse(time.RFC3339, strings.TrimSpace(record[2]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expires_at format, use RFC3339", line)
+			}
+			input.ExpiresAt = &expiresAt
+		}
+		inputs = append(inputs, input)
+	}
+}
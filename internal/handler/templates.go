@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"insider-challenge/internal/service"
+	domain "insider-challenge/pkg/domain"
+)
+
+// CreateTemplateRequest represents a new version of a template
+type CreateTemplateRequest struct {
+	ID          string            `json:"id" example:"otp"`
+	Description string            `json:"description,omitempty" example:"Login verification code"`
+	Variants    map[string]string `json:"variants"`
+}
+
+// PreviewTemplateRequest represents the values a template is previewed with
+type PreviewTemplateRequest struct {
+	Version   int               `json:"version,omitempty" example:"2"`
+	Locale    string            `json:"locale,omitempty" example:"tr"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// PreviewTemplateResponse represents a rendered template
+type PreviewTemplateResponse struct {
+	TemplateID string `json:"template_id"`
+	Version    int    `json:"version"`
+	Locale     string `json:"locale"`
+	Content    string `json:"content"`
+	Length     int    `json:"length"`
+	MaxLength  int    `json:"max_length"`
+	Fits       bool   `json:"fits"` // Whether a message can be created with the content
+	Encoding   string `json:"encoding"`
+	Segments   int    `json:"segments"`
+}
+
+// handleTemplates dispatches the template list and create routes
+func (h *Handler) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleListTemplates(w, r)
+	case http.MethodPost:
+		h.handleCreateTemplate(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// @Summary List templates
+// @Description Retrieves the latest version of every template
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.Template
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [get]
+func (h *Handler) handleListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.service.ListTemplates(r.Context())
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, templates)
+}
+
+// @Summary Create template version
+// @Description Stores the variants as the next version of the template, versions are immutable. Variant bodies use {{.Var}} placeholders and are keyed by locale (tr, en).
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param template body CreateTemplateRequest true "Template"
+// @Success 201 {object} domain.Template
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates [post]
+func (h *Handler) handleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req CreateTemplateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tmpl, err := h.service.CreateTemplate(r.Context(), service.TemplateInput(req))
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tmpl)
+}
+
+// @Summary Get template
+// @Description Retrieves a version of a template, the latest when version is not set
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param version query int false "Template version"
+// @Success 200 {object} domain.Template
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{id} [get]
+func (h *Handler) handleTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var version int
+	if versionStr := r.URL.Query().Get("version"); versionStr != "" {
+		v, err := strconv.Atoi(versionStr)
+		if err != nil || v < 1 {
+			writeError(w, http.StatusBadRequest, "Invalid version")
+			return
+		}
+		version = v
+	}
+
+	tmpl, err := h.service.GetTemplate(r.Context(), r.PathValue("id"), version)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+// @Summary Preview template
+// @Description Renders a template variant with the given variables without creating a message
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param preview body PreviewTemplateRequest true "Version, locale and variables"
+// @Success 200 {object} PreviewTemplateResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /templates/{id}/preview [post]
+func (h *Handler) handlePreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req PreviewTemplateRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rendered, err := h.service.RenderTemplate(r.Context(), service.RenderInput{
+		TemplateID: r.PathValue("id"),
+		Version:    req.Version,
+		Locale:     req.Locale,
+		Variables:  req.Variables,
+	})
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	length := utf8.RuneCountInString(rendered.Content)
+	analysis := h.service.AnalyzeContent(rendered.Content)
+	writeJSON(w, http.StatusOK, PreviewTemplateResponse{
+		TemplateID: rendered.TemplateID,
+		Version:    rendered.Version,
+		Locale:     rendered.Locale,
+		Content:    rendered.Content,
+		Length:     length,
+		MaxLength:  domain.MaxContentLength,
+		Fits:       length <= domain.MaxContentLength,
+		Encoding:   string(analysis.Encoding),
+		Segments:   analysis.Segments,
+	})
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+)
+
+// TriggerResponse represents the result of a manually triggered sending cycle
+type TriggerResponse struct {
+	Sent       int `json:"sent"`
+	Failed     int `json:"failed"`
+	Suppressed int `json:"suppressed"`
+	Blocked    int `json:"blocked"`    // Destination country not allowed by the country policy
+	Deferred   int `json:"deferred"`   // Postponed by quiet hours or the country rate limit
+	Duplicates int `json:"duplicates"` // Same content already sent to the recipient within the dedup window
+}
+
+// @Summary Trigger message sender
+// @Description Runs one sending cycle immediately without waiting for the ticker, serialized with the ticker. Answers 409 while the sender is stopped with /stop.
+// @Tags message
+// @Accept json
+// @Produce json
+// @Success 200 {object} TriggerResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sender/trigger [post]
+func (h *Handler) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	result, err := h.service.TriggerMessageSender(r.Context())
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TriggerResponse{
+		Sent:       result.Sent,
+		Failed:     result.Failed,
+		Suppressed: result.Suppressed,
+		Blocked:    result.Blocked,
+		Deferred:   result.Deferred,
+		Duplicates: result.Duplicates,
+	})
+}
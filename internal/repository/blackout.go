@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// UpsertBlackoutDate adds a date to the blackout calendar, an existing date is replaced
+func (r *repository) UpsertBlackoutDate(ctx context.Context, blackout *domain.BlackoutDate) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"description", "updated_at"}),
+		}).
+		Create(blackout).Error
+	if err != nil {
+		return errors.Wrap(err, "upsert blackout date")
+	}
+	return nil
+}
+
+// ListBlackoutDates retrieves the blackout dates from the given date on, oldest first
+func (r *repository) ListBlackoutDates(ctx context.Context, from string) ([]domain.BlackoutDate, error) {
+	var blackouts []domain.BlackoutDate
+	err := r.db.WithContext(ctx).
+		Where("date >= ?", from).
+		Order("date ASC").
+		Find(&blackouts).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list blackout dates")
+	}
+	return blackouts, nil
+}
+
+// DeleteBlackoutDate removes a date from the blackout calendar
+func (r *repository) DeleteBlackoutDate(ctx context.Context, date string) error {
+	result := r.db.WithContext(ctx).Where("date = ?", date).Delete(&domain.BlackoutDate{})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "delete blackout date")
+	}
+	if result.RowsAffected == 0 {
+		return errors.Wrap(errors.ErrBlackoutDateNotFound, fmt.Sprintf("date: %s", date))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// CreateCallbackDelivery queues a status callback
+func (r *repository) CreateCallbackDelivery(ctx context.Context, delivery *domain.CallbackDelivery) error {
+	if err := r.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		return errors.Wrap(err, "create callback delivery")
+	}
+	return nil
+}
+
+// ClaimDueCallbacks locks up to limit due callbacks for the lease duration so other replicas skip them
+func (r *repository) ClaimDueCallbacks(ctx context.Context, limit int, lease time.Duration) ([]domain.CallbackDelivery, error) {
+	var deliveries []domain.CallbackDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", domain.CallbackStatusPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil {
+			return errors.Wrap(err, "find due callbacks")
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]interface{}, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+
+		// Pushing next_attempt_at forward is the lease, a crashed replica's claims become due again
+		err = tx.Model(&domain.CallbackDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(lease)).Error
+		if err != nil {
+			return errors.Wrap(err, "claim due callbacks")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RecordCallbackAttempt logs a callback attempt and updates the delivery state
+func (r *repository) RecordCallbackAttempt(ctx context.Context, delivery *domain.CallbackDelivery, attempt domain.CallbackAttempt) error {
+	attempt.Error = truncate(attempt.Error, maxErrorLength)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attempt).Error; err != nil {
+			return errors.Wrap(err, "create callback attempt")
+		}
+
+		updates := map[string]interface{}{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": attempt.StatusCode,
+			"last_error":       attempt.Error,
+			"delivered_at":     delivery.DeliveredAt,
+		}
+		if err := tx.Model(delivery).Updates(updates).Error; err != nil {
+			return errors.Wrap(err, "update callback delivery")
+		}
+		return nil
+	})
+}
+
+// GetCallbackDeliveries retrieves the callbacks of a message with their delivery log
+func (r *repository) GetCallbackDeliveries(ctx context.Context, messageID string) ([]domain.CallbackDelivery, error) {
+	var deliveries []domain.CallbackDelivery
+	err := r.db.WithContext(ctx).
+		Preload("Log", func(db *gorm.DB) *gorm.DB { return db.Order("attempt ASC") }).
+		Where("message_id = ?", messageID).
+		Order("created_at ASC").
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get callback deliveries")
+	}
+	return deliveries, nil
+}
@@ -21,8 +21,7 @@ const (
 
 // InitDB initialize the db connection
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+	dsn := buildDSN(cfg)
 
 	// Configure logger
 	gormLogger := logger.New(
@@ -53,10 +52,31 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(connMaxLifetime)
 
 	// Auto migrate database schema
-	if err := db.AutoMigrate(&domain.Message{}); err != nil {
+	if err := db.AutoMigrate(
+		&domain.Message{},
+		&domain.MessageStatsRollup{},
+		&domain.MessageStatusRollup{},
+		&domain.LatencyRollup{},
+		&domain.FailureRollup{},
+		&domain.CallbackDelivery{},
+		&domain.CallbackAttempt{},
+		&domain.DeliveryReceipt{},
+		&domain.MessageEvent{},
+		&domain.Suppression{},
+		&domain.InboundMessage{},
+		&domain.Template{},
+		&domain.BlackoutDate{},
+		&domain.IdempotencyKey{},
+		&domain.SenderSetting{},
+	); err != nil {
 		return nil, fmt.Errorf("migrate database: %w", err)
 	}
 
+	// Apply schema objects AutoMigrate does not manage (triggers, functions)
+	if err := runMigrations(db, cfg); err != nil {
+		return nil, fmt.Errorf("run migrations: %w", err)
+	}
+
 	// Initialize sample data if database is empty
 	// @todo: remove this if working production
 	if err := InitSampleData(db); err != nil {
@@ -65,3 +85,9 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// buildDSN builds the postgres connection string from the configuration
+func buildDSN(cfg *config.Config) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
+}
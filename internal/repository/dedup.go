@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// FindDedupOriginal retrieves the oldest pending or sent message with the content hash created since the given time, nil when there is none
+func (r *repository) FindDedupOriginal(ctx context.Context, contentHash string, since time.Time) (*domain.Message, error) {
+	var message domain.Message
+	err := r.db.WithContext(ctx).
+		Where("content_hash = ? AND created_at >= ? AND status IN ? AND deleted_at IS NULL",
+			contentHash, since, []domain.MessageStatus{domain.MessageStatusPending, domain.MessageStatusSent}).
+		Order("created_at ASC").
+		First(&message).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "find dedup original")
+	}
+	return &message, nil
+}
+
+// FindSentDuplicate retrieves a message other than messageID with the content hash sent since the given time, nil when there is none
+func (r *repository) FindSentDuplicate(ctx context.Context, contentHash, messageID string, since time.Time) (*domain.Message, error) {
+	var message domain.Message
+	err := r.db.WithContext(ctx).
+		Where("content_hash = ? AND id <> ? AND status = ? AND sent_at >= ?",
+			contentHash, messageID, domain.MessageStatusSent, since).
+		Order("sent_at ASC").
+		First(&message).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "find sent duplicate")
+	}
+	return &message, nil
+}
+
+// MarkMessageDuplicate moves a pending message to the duplicate terminal state and links it to the message it repeats
+func (r *repository) MarkMessageDuplicate(ctx context.Context, messageID string, originalID uuid.UUID) error {
+	return r.closePendingMessage(ctx, messageID, domain.MessageStatusDuplicate, "duplicate of "+originalID.String(), map[string]interface{}{
+		"duplicate_of": originalID,
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+const (
+	// exportCursorName server side cursor name, cursors are scoped to their transaction
+	exportCursorName = "sent_messages_export"
+
+	// exportFetchSize number of rows fetched from the cursor per round trip
+	exportFetchSize = 1000
+)
+
+// StreamSentMessages streams the sent messages matching the filter in (sent_at, id) order through a server side cursor.
+// Memory use is bounded by the fetch size regardless of the number of rows.
+func (r *repository) StreamSentMessages(ctx context.Context, filter domain.MessageFilter, after *domain.MessageCursor, fn func(domain.Message) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET TRANSACTION READ ONLY").Error; err != nil {
+			return errors.Wrap(err, "set read only transaction")
+		}
+
+		direction := "DESC"
+		comparison := "<"
+		if filter.SortAsc {
+			direction, comparison = "ASC", ">"
+		}
+
+		query := applyMessageFilter(tx.Model(&domain.Message{}), filter).
+			Where("status = ?", domain.MessageStatusSent)
+		if after != nil {
+			query = query.Where("(sent_at, id) "+comparison+" (?, ?)", after.SentAt, after.ID)
+		}
+
+		stmt := query.
+			Order("sent_at " + direction + ", id " + direction).
+			Session(&gorm.Session{DryRun: true}).
+			Find(&[]domain.Message{}).
+			Statement
+
+		// The dry run statement already uses postgres placeholders, so it goes to the driver as is
+		declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", exportCursorName, stmt.SQL.String())
+		if _, err := tx.Statement.ConnPool.ExecContext(ctx, declare, stmt.Vars...); err != nil {
+			return errors.Wrap(err, "declare export cursor")
+		}
+
+		fetch := fmt.Sprintf("FETCH FORWARD %d FROM %s", exportFetchSize, exportCursorName)
+		for {
+			var batch []domain.Message
+			if err := tx.Raw(fetch).Scan(&batch).Error; err != nil {
+				return errors.Wrap(err, "fetch export cursor")
+			}
+
+			for _, msg := range batch {
+				if err := fn(msg); err != nil {
+					return err
+				}
+			}
+
+			if len(batch) < exportFetchSize {
+				return nil
+			}
+		}
+	})
+}
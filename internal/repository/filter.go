@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+)
+
+// sortColumns maps the whitelisted sort fields to their quoted columns
+var sortColumns = map[string]string{
+	domain.SortBySentAt:    "sent_at",
+	domain.SortByCreatedAt: "created_at",
+	domain.SortByTo:        `"to"`,
+}
+
+// likeEscaper escapes the LIKE wildcards in user input
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// sentMessagesQuery builds the query of sent messages matching the filter
+func (r *repository) sentMessagesQuery(ctx context.Context, filter domain.MessageFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).
+		Where("status = ? AND deleted_at IS NULL", domain.MessageStatusSent)
+	return applyMessageFilter(query, filter)
+}
+
+// applyMessageFilter adds the filter conditions to the query
+func applyMessageFilter(query *gorm.DB, filter domain.MessageFilter) *gorm.DB {
+	if filter.To != "" {
+		query = query.Where(`"to" = ?`, filter.To)
+	}
+	if filter.ToPrefix != "" {
+		query = query.Where(`"to" LIKE ?`, likeEscaper.Replace(filter.ToPrefix)+"%")
+	}
+	if filter.SentAfter != nil {
+		query = query.Where("sent_at >= ?", *filter.SentAfter)
+	}
+	if filter.SentBefore != nil {
+		query = query.Where("sent_at < ?", *filter.SentBefore)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.ContentContains != "" {
+		query = query.Where("content ILIKE ?", "%"+likeEscaper.Replace(filter.ContentContains)+"%")
+	}
+	return query
+}
+
+// sentMessagesOrder builds the order clause, ids break ties so pages are stable
+func sentMessagesOrder(filter domain.MessageFilter) string {
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = sortColumns[domain.SortBySentAt]
+	}
+
+	direction := "DESC"
+	if filter.SortAsc {
+		direction = "ASC"
+	}
+	return column + " " + direction + ", id " + direction
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// RecordMessageEvent appends an entry to the message history
+func (r *repository) RecordMessageEvent(ctx context.Context, event *domain.MessageEvent) error {
+	return appendMessageEvents(r.db.WithContext(ctx), event)
+}
+
+// GetMessageEvents retrieves the history of a message in the order it happened
+func (r *repository) GetMessageEvents(ctx context.Context, messageID string) ([]domain.MessageEvent, error) {
+	var events []domain.MessageEvent
+	err := r.db.WithContext(ctx).
+		Where("message_id = ?", messageID).
+		Order("id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get message events")
+	}
+	return events, nil
+}
+
+// appendMessageEvents inserts history entries with db, pass the transaction to record them atomically with the change
+func appendMessageEvents(db *gorm.DB, events ...*domain.MessageEvent) error {
+	for _, event := range events {
+		event.ResponseBody = truncate(event.ResponseBody, domain.MaxResponseBodyLength)
+		event.Error = truncate(event.Error, maxErrorLength)
+		event.Detail = truncate(event.Detail, maxErrorLength)
+	}
+	if err := db.Create(events).Error; err != nil {
+		return errors.Wrap(err, "create message events")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// idempotencyPurgeBatch expired keys removed by every new key, which keeps the table bounded without a separate job
+const idempotencyPurgeBatch = 100
+
+// GetIdempotencyKey retrieves the live key, nil when it was never used or expired
+func (r *repository) GetIdempotencyKey(ctx context.Context, clientID, key string) (*domain.IdempotencyKey, error) {
+	var found domain.IdempotencyKey
+	err := r.db.WithContext(ctx).
+		Where("client_id = ? AND key = ? AND expires_at > ?", clientID, key, time.Now()).
+		First(&found).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "get idempotency key")
+	}
+	return &found, nil
+}
+
+// CreateMessageIdempotent creates the message unless the key was used before. It returns the live key of the earlier
+// request instead, the caller compares its request hash. Concurrent requests with the same key are serialized.
+func (r *repository) CreateMessageIdempotent(ctx context.Context, message *domain.Message, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	var existing *domain.IdempotencyKey
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "idempotency:"+key.ClientID+":"+key.Key).Error; err != nil {
+			return errors.Wrap(err, "lock idempotency key")
+		}
+
+		now := time.Now()
+		if err := tx.Exec(`DELETE FROM idempotency_keys WHERE ctid IN (
+			SELECT ctid FROM idempotency_keys WHERE expires_at <= ? LIMIT ?)`, now, idempotencyPurgeBatch).Error; err != nil {
+			return errors.Wrap(err, "purge idempotency keys")
+		}
+
+		var found domain.IdempotencyKey
+		err := tx.Where("client_id = ? AND key = ? AND expires_at > ?", key.ClientID, key.Key, now).First(&found).Error
+		if err == nil {
+			existing = &found
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return errors.Wrap(err, "find idempotency key")
+		}
+
+		// An expired key that was not purged yet is replaced
+		if err := tx.Where("client_id = ? AND key = ?", key.ClientID, key.Key).Delete(&domain.IdempotencyKey{}).Error; err != nil {
+			return errors.Wrap(err, "delete expired idempotency key")
+		}
+
+		if err := createMessage(tx, message); err != nil {
+			return err
+		}
+		key.MessageID = message.ID
+		if err := tx.Create(key).Error; err != nil {
+			return errors.Wrap(err, "create idempotency key")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
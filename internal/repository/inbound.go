@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// CreateInboundMessage stores a reply linked to the most recent message sent to its sender and applies its opt-out in the
+// same transaction. A reply the provider already reported with the same message id is not stored again, it is returned
+// instead and its opt-out is not applied twice.
+func (r *repository) CreateInboundMessage(ctx context.Context, inbound *domain.InboundMessage, optOut *domain.Suppression) (*domain.InboundMessage, error) {
+	var existing *domain.InboundMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		err := tx.Model(&domain.Message{}).
+			Where(`"to" = ? AND status = ? AND deleted_at IS NULL`, inbound.From, domain.MessageStatusSent).
+			Order("sent_at DESC").
+			Limit(1).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return errors.Wrap(err, "find replied message")
+		}
+		if len(ids) > 0 {
+			inbound.MessageID = &ids[0]
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "provider"}, {Name: "provider_message_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "provider_message_id <> ''"}}},
+			DoNothing:   true,
+		}).Create(inbound)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "create inbound message")
+		}
+
+		if result.RowsAffected == 0 {
+			var reported domain.InboundMessage
+			err := tx.Where("provider = ? AND provider_message_id = ?", inbound.Provider, inbound.ProviderMessageID).
+				First(&reported).Error
+			if err != nil {
+				return errors.Wrap(err, "find reported inbound message")
+			}
+			existing = &reported
+			return nil
+		}
+
+		if optOut == nil {
+			return nil
+		}
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "phone"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "source", "expires_at", "updated_at"}),
+		}).Create(optOut).Error
+		if err != nil {
+			return errors.Wrap(err, "opt out sender")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// GetInboundMessages retrieves a page of the replies received from a phone number, newest first
+func (r *repository) GetInboundMessages(ctx context.Context, from string, offset, limit int) ([]domain.InboundMessage, error) {
+	var messages []domain.InboundMessage
+	err := r.db.WithContext(ctx).
+		Where(`"from" = ?`, from).
+		Order("received_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get inbound messages")
+	}
+	return messages, nil
+}
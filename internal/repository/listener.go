@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"insider-challenge/pkg/config"
+)
+
+const (
+	listenerMinBackoff = 1 * time.Second
+	listenerMaxBackoff = 30 * time.Second
+)
+
+// Listener receives postgres notifications on a dedicated connection outside the gorm pool
+type Listener struct {
+	dsn     string
+	channel string
+}
+
+// NewListener creates a new listener for the given notification channel
+func NewListener(cfg *config.Config, channel string) *Listener {
+	return &Listener{
+		dsn:     buildDSN(cfg),
+		channel: channel,
+	}
+}
+
+// Listen calls notify for every notification until ctx is done, reconnecting with backoff on failures
+func (l *Listener) Listen(ctx context.Context, notify func()) {
+	backoff := listenerMinBackoff
+	for {
+		err := l.listen(ctx, notify, func() { backoff = listenerMinBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Listener on %s failed, reconnecting in %s: %v", l.channel, backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+
+		backoff *= 2
+		if backoff > listenerMaxBackoff {
+			backoff = listenerMaxBackoff
+		}
+	}
+}
+
+// listen holds a single connection until it fails
+func (l *Listener) listen(ctx context.Context, notify func(), connected func()) error {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+	connected()
+
+	// Notifications sent while we were disconnected are lost, wake once to catch up
+	notify()
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		notify()
+	}
+}
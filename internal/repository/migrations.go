@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/config"
+)
+
+// migrationLockID advisory lock key that serializes migrations between replicas booting together
+const migrationLockID = 7301562001
+
+// MessageCreatedChannel postgres notification channel fired after messages are inserted
+const MessageCreatedChannel = "messages_created"
+
+// migrations are idempotent statements applied after AutoMigrate on every boot
+var migrations = []string{
+	// One notification per INSERT statement so bulk imports wake the sender once
+	`CREATE OR REPLACE FUNCTION notify_message_created() RETURNS trigger AS $$
+	BEGIN
+		PERFORM pg_notify('` + MessageCreatedChannel + `', '');
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS messages_created_notify ON messages`,
+	`CREATE TRIGGER messages_created_notify
+		AFTER INSERT ON messages
+		FOR EACH STATEMENT EXECUTE FUNCTION notify_message_created()`,
+
+	// Every status transition moves the message between the counters of message_status_rollups, so /stats never scans messages
+	`CREATE OR REPLACE FUNCTION count_message_status() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'UPDATE' AND OLD.status = NEW.status AND OLD.provider IS NOT DISTINCT FROM NEW.provider
+			AND OLD.created_at = NEW.created_at THEN
+			RETURN NULL;
+		END IF;
+		IF TG_OP IN ('UPDATE', 'DELETE') THEN
+			INSERT INTO message_status_rollups (bucket, provider, status, count)
+			VALUES (date_trunc('minute', OLD.created_at), COALESCE(OLD.provider, ''), OLD.status, -1)
+			ON CONFLICT (bucket, provider, status) DO UPDATE SET count = message_status_rollups.count - 1;
+		END IF;
+		IF TG_OP IN ('INSERT', 'UPDATE') THEN
+			INSERT INTO message_status_rollups (bucket, provider, status, count)
+			VALUES (date_trunc('minute', NEW.created_at), COALESCE(NEW.provider, ''), NEW.status, 1)
+			ON CONFLICT (bucket, provider, status) DO UPDATE SET count = message_status_rollups.count + 1;
+		END IF;
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS messages_count_status ON messages`,
+	`CREATE TRIGGER messages_count_status
+		AFTER INSERT OR DELETE OR UPDATE OF status, provider, created_at ON messages
+		FOR EACH ROW EXECUTE FUNCTION count_message_status()`,
+
+	// Backfill the status column for rows created before it existed
+	`UPDATE messages SET status = 'sent' WHERE is_sent AND status = 'pending'`,
+	`UPDATE messages SET status = 'cancelled' WHERE deleted_at IS NOT NULL AND status = 'pending'`,
+
+	// Receipts applied before last_receipt_at existed are ordered by when they were applied
+	`UPDATE messages SET last_receipt_at = COALESCE(delivered_at, updated_at)
+		WHERE delivery_status IS NOT NULL AND delivery_status <> '' AND last_receipt_at IS NULL`,
+
+	// Partial indexes backing the /sent filters and sort fields
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_sent_sent_at ON messages (sent_at DESC, id DESC)
+		WHERE status = 'sent' AND deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_sent_created_at ON messages (created_at DESC, id DESC)
+		WHERE status = 'sent' AND deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_sent_to ON messages ("to" text_pattern_ops, sent_at DESC)
+		WHERE status = 'sent' AND deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_sent_provider ON messages (provider, sent_at DESC)
+		WHERE status = 'sent' AND deleted_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_messages_content_trgm ON messages USING gin (content gin_trgm_ops)`,
+
+	// The sender fills each batch lane by lane in creation order
+	`CREATE INDEX IF NOT EXISTS idx_messages_pending_lane ON messages (priority, created_at)
+		WHERE status = 'pending' AND deleted_at IS NULL`,
+
+	// Provider retries of an inbound reply are stored once, earlier duplicates are dropped before the index is created
+	`DELETE FROM inbound_messages a USING inbound_messages b
+		WHERE a.provider = b.provider AND a.provider_message_id = b.provider_message_id AND a.provider_message_id <> ''
+		AND (a.created_at, a.id) > (b.created_at, b.id)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_inbound_messages_provider_message ON inbound_messages (provider, provider_message_id)
+		WHERE provider_message_id <> ''`,
+
+	// One-shot data migrations that already ran
+	`CREATE TABLE IF NOT EXISTS data_migrations (
+		name varchar(100) PRIMARY KEY,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`,
+
+	// Due callbacks are claimed by next_attempt_at
+	`CREATE INDEX IF NOT EXISTS idx_callback_deliveries_due ON callback_deliveries (next_attempt_at)
+		WHERE status = 'pending'`,
+}
+
+// dataMigration rewrites existing rows once per database, data_migrations records the ones that ran
+type dataMigration struct {
+	name string
+	run  func(tx *gorm.DB, cfg *config.Config) error
+}
+
+// dataMigrations are applied in order after the migrations, in the same transaction
+var dataMigrations = []dataMigration{
+	// Messages created before the status trigger existed, the trigger counts every later change. The counters are rebuilt
+	// from scratch since the migrations above already ran through the trigger, writes wait for the trigger's table lock.
+	{name: "backfill_message_status_rollups", run: func(tx *gorm.DB, _ *config.Config) error {
+		if err := tx.Exec("DELETE FROM message_status_rollups").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO message_status_rollups (bucket, provider, status, count)
+			SELECT date_trunc('minute', created_at), COALESCE(provider, ''), status, COUNT(*)
+			FROM messages GROUP BY 1, 2, 3`).Error
+	}},
+
+	// Pending recipients and suppressions stored before numbers were normalized to E.164, later numbers are normalized on write
+	{name: "normalize_phone_numbers", run: func(tx *gorm.DB, cfg *config.Config) error {
+		return normalizePhoneNumbers(tx, cfg.DefaultRegion)
+	}},
+}
+
+// runMigrations applies the raw sql migrations and the pending data migrations in a single transaction
+func runMigrations(db *gorm.DB, cfg *config.Config) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+
+		for i, statement := range migrations {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("migration %d: %w", i, err)
+			}
+		}
+
+		for _, migration := range dataMigrations {
+			// The marker is inserted first, a failing migration rolls it back with everything else
+			result := tx.Exec("INSERT INTO data_migrations (name) VALUES (?) ON CONFLICT DO NOTHING", migration.name)
+			if result.Error != nil {
+				return fmt.Errorf("record data migration %s: %w", migration.name, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			if err := migration.run(tx, cfg); err != nil {
+				return fmt.Errorf("data migration %s: %w", migration.name, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/phone"
+)
+
+// e164Pattern matches numbers already stored in E.164, everything else was stored before numbers were normalized
+const e164Pattern = `^\+[0-9]+$`
+
+// normalizePhoneNumbers rewrites the pending recipients and the suppressions stored before numbers were normalized to
+// E.164, a suppression stored as 0555... would otherwise never match the normalized recipient. Sent and cancelled
+// messages keep the number they were stored with. Pending messages to numbers that do not parse are failed and logged,
+// suppressions that do not parse are logged and kept. Cached lookups need no refresh: suppressed lookups are always read
+// from the database and lookups without a suppression expire within seconds.
+func normalizePhoneNumbers(tx *gorm.DB, defaultRegion string) error {
+	if err := normalizeRecipients(tx, defaultRegion); err != nil {
+		return err
+	}
+
+	migrated, err := normalizeSuppressions(tx, defaultRegion)
+	if err != nil {
+		return err
+	}
+	if migrated > 0 {
+		log.Printf("Normalized %d suppressed numbers to E.164", migrated)
+	}
+	return nil
+}
+
+// normalizeRecipients rewrites the recipients of pending messages
+func normalizeRecipients(tx *gorm.DB, defaultRegion string) error {
+	var recipients []string
+	err := tx.Model(&domain.Message{}).
+		Where(`"to" !~ ? AND status = ?`, e164Pattern, domain.MessageStatusPending).
+		Distinct(`"to"`).
+		Pluck(`"to"`, &recipients).Error
+	if err != nil {
+		return errors.Wrap(err, "find unnormalized recipients")
+	}
+
+	for _, to := range recipients {
+		number, err := phone.Parse(to, defaultRegion)
+		if err != nil {
+			log.Printf("Stored recipient %q is not a valid phone number, failing its pending messages: %v", to, err)
+			if err := failPendingMessages(tx, to, "invalid recipient: "+err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = tx.Model(&domain.Message{}).
+			Where(`"to" = ? AND status = ?`, to, domain.MessageStatusPending).
+			Updates(map[string]interface{}{"to": number.E164, "country": number.Region}).Error
+		if err != nil {
+			return errors.Wrap(err, "normalize recipient")
+		}
+	}
+	return nil
+}
+
+// failPendingMessages moves the pending messages to the recipient to the failed state without an attempt
+func failPendingMessages(tx *gorm.DB, to, reason string) error {
+	var messages []domain.Message
+	if err := tx.Where(`"to" = ? AND status = ? AND deleted_at IS NULL`, to, domain.MessageStatusPending).Find(&messages).Error; err != nil {
+		return errors.Wrap(err, "find pending messages")
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	reason = truncate(reason, maxErrorLength)
+	events := make([]*domain.MessageEvent, len(messages))
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID.String()
+		events[i] = &domain.MessageEvent{
+			MessageID:  message.ID,
+			Kind:       domain.MessageEventTransition,
+			FromStatus: domain.MessageStatusPending,
+			ToStatus:   domain.MessageStatusFailed,
+			Error:      reason,
+		}
+	}
+
+	err := tx.Model(&domain.Message{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"status": domain.MessageStatusFailed, "last_error": reason}).Error
+	if err != nil {
+		return errors.Wrap(err, "fail pending messages")
+	}
+	return appendMessageEvents(tx, events...)
+}
+
+// normalizeSuppressions rewrites the suppressed numbers, an entry whose normalized number is already suppressed is dropped
+func normalizeSuppressions(tx *gorm.DB, defaultRegion string) (int, error) {
+	var entries []domain.Suppression
+	if err := tx.Where("phone !~ ?", e164Pattern).Find(&entries).Error; err != nil {
+		return 0, errors.Wrap(err, "find unnormalized suppressions")
+	}
+
+	migrated := 0
+	for _, entry := range entries {
+		number, err := phone.Parse(entry.Phone, defaultRegion)
+		if err != nil {
+			log.Printf("Suppressed number %q is not a valid phone number, it matches no recipient: %v", entry.Phone, err)
+			continue
+		}
+
+		var existing int64
+		if err := tx.Model(&domain.Suppression{}).Where("phone = ?", number.E164).Count(&existing).Error; err != nil {
+			return 0, errors.Wrap(err, "find normalized suppression")
+		}
+
+		if existing > 0 {
+			err = tx.Where("phone = ?", entry.Phone).Delete(&domain.Suppression{}).Error
+		} else {
+			err = tx.Model(&domain.Suppression{}).Where("phone = ?", entry.Phone).Update("phone", number.E164).Error
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("normalize suppression %s", entry.Phone))
+		}
+		migrated++
+	}
+	return migrated, nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// MarkMessageBlocked moves a pending message to the blocked terminal state
+func (r *repository) MarkMessageBlocked(ctx context.Context, messageID, reason string) error {
+	return r.closePendingMessage(ctx, messageID, domain.MessageStatusBlocked, reason, nil)
+}
+
+// DeferMessage hides a pending message from the sender until the given time and records why in its history
+func (r *repository) DeferMessage(ctx context.Context, messageID string, until time.Time, reason string) error {
+	id, err := uuid.Parse(messageID)
+	if err != nil {
+		return errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("message ID: %s", messageID))
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Message{}).
+			Where("id = ? AND status = ? AND deleted_at IS NULL", id, domain.MessageStatusPending).
+			Update("not_before", until)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "defer message")
+		}
+		if result.RowsAffected == 0 {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s", messageID))
+		}
+
+		return appendMessageEvents(tx, &domain.MessageEvent{
+			MessageID: id,
+			Kind:      domain.MessageEventDeferral,
+			Detail:    truncate(fmt.Sprintf("%s, deferred until %s", reason, until.UTC().Format(time.RFC3339)), maxErrorLength),
+		})
+	})
+}
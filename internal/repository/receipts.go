@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// RecordDeliveryReceipt stores a provider receipt and applies it to the message it belongs to.
+// The returned message is nil when the receipt arrived before the message was marked sent,
+// MarkMessageAsSent applies it once the provider message id is committed.
+func (r *repository) RecordDeliveryReceipt(ctx context.Context, receipt *domain.DeliveryReceipt) (*domain.Message, error) {
+	var matched *domain.Message
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockProviderMessage(tx, receipt.Provider, receipt.ProviderMessageID); err != nil {
+			return err
+		}
+
+		if err := tx.Create(receipt).Error; err != nil {
+			return errors.Wrap(err, "create delivery receipt")
+		}
+
+		var message domain.Message
+		err := tx.Where("provider = ? AND provider_message_id = ?", receipt.Provider, receipt.ProviderMessageID).
+			Order("sent_at DESC").
+			Limit(1).
+			Find(&message).Error
+		if err != nil {
+			return errors.Wrap(err, "find message by provider message id")
+		}
+		if message.ID == uuid.Nil {
+			return nil
+		}
+
+		if err := applyDeliveryReceipts(tx, &message, []domain.DeliveryReceipt{*receipt}); err != nil {
+			return err
+		}
+		matched = &message
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// applyPendingReceipts applies the receipts that arrived before the message was marked sent
+func applyPendingReceipts(tx *gorm.DB, message *domain.Message) error {
+	if message.ProviderMessageID == "" {
+		return nil
+	}
+	if err := lockProviderMessage(tx, message.Provider, message.ProviderMessageID); err != nil {
+		return err
+	}
+
+	var receipts []domain.DeliveryReceipt
+	err := tx.Where("provider = ? AND provider_message_id = ? AND message_id IS NULL", message.Provider, message.ProviderMessageID).
+		Find(&receipts).Error
+	if err != nil {
+		return errors.Wrap(err, "find pending delivery receipts")
+	}
+	if len(receipts) == 0 {
+		return nil
+	}
+	return applyDeliveryReceipts(tx, message, receipts)
+}
+
+// applyDeliveryReceipts links the receipts to the message and records the latest reported status. Receipts arrive out of
+// order, so the status only moves to a receipt newer than the one it came from and never from a final status back to an
+// intermediate one.
+func applyDeliveryReceipts(tx *gorm.DB, message *domain.Message, receipts []domain.DeliveryReceipt) error {
+	ids := make([]uint, len(receipts))
+	for i, receipt := range receipts {
+		ids[i] = receipt.ID
+	}
+
+	now := time.Now()
+	err := tx.Model(&domain.DeliveryReceipt{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{"message_id": message.ID, "applied_at": now}).Error
+	if err != nil {
+		return errors.Wrap(err, "link delivery receipts")
+	}
+
+	latest := latestReceipt(receipts)
+	updates := map[string]interface{}{
+		"delivery_status": latest.Status,
+		"delivered_at":    nil,
+		"last_receipt_at": latest.OccurredAt,
+	}
+	if latest.Status == domain.DeliveryStatusDelivered {
+		updates["delivered_at"] = latest.OccurredAt
+	}
+
+	query := tx.Model(&domain.Message{}).Where("id = ?", message.ID)
+	if latest.Status.IsFinal() {
+		// A final status replaces an intermediate one even when the intermediate receipt carries a later time
+		query = query.Where("last_receipt_at IS NULL OR last_receipt_at < ? OR COALESCE(delivery_status, '') NOT IN ?",
+			latest.OccurredAt, domain.FinalDeliveryStatuses)
+	} else {
+		query = query.Where("(last_receipt_at IS NULL OR last_receipt_at < ?) AND COALESCE(delivery_status, '') NOT IN ?",
+			latest.OccurredAt, domain.FinalDeliveryStatuses)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "update delivery status")
+	}
+	if result.RowsAffected > 0 {
+		message.DeliveryStatus = latest.Status
+		message.LastReceiptAt = &latest.OccurredAt
+		message.DeliveredAt = nil
+		if latest.Status == domain.DeliveryStatusDelivered {
+			message.DeliveredAt = &latest.OccurredAt
+		}
+	}
+
+	events := make([]*domain.MessageEvent, len(receipts))
+	for i, receipt := range receipts {
+		events[i] = &domain.MessageEvent{
+			MessageID: message.ID,
+			Kind:      domain.MessageEventReceipt,
+			Provider:  receipt.Provider,
+			Detail:    string(receipt.Status),
+		}
+	}
+	return appendMessageEvents(tx, events...)
+}
+
+// latestReceipt picks the receipt that decides the delivery status, a final receipt wins over intermediate ones and the
+// latest of the same kind wins
+func latestReceipt(receipts []domain.DeliveryReceipt) domain.DeliveryReceipt {
+	latest := receipts[0]
+	for _, receipt := range receipts[1:] {
+		switch {
+		case receipt.Status.IsFinal() != latest.Status.IsFinal():
+			if receipt.Status.IsFinal() {
+				latest = receipt
+			}
+		case receipt.OccurredAt.After(latest.OccurredAt):
+			latest = receipt
+		}
+	}
+	return latest
+}
+
+// lockProviderMessage serializes receipts and the sent update of the same provider message id,
+// without it a receipt and the sent update committing together would both miss each other
+func lockProviderMessage(tx *gorm.DB, provider, providerMessageID string) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", provider+":"+providerMessageID).Error; err != nil {
+		return errors.Wrap(err, "lock provider message id")
+	}
+	return nil
+}
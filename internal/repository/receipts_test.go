@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/domain"
+)
+
+func TestLatestReceipt(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	receipt := func(id uint, status domain.DeliveryStatus, offset time.Duration) domain.DeliveryReceipt {
+		return domain.DeliveryReceipt{ID: id, Status: status, OccurredAt: base.Add(offset)}
+	}
+
+	tests := []struct {
+		name     string
+		receipts []domain.DeliveryReceipt
+		want     uint
+	}{
+		{
+			name:     "single receipt",
+			receipts: []domain.DeliveryReceipt{receipt(1, domain.DeliveryStatusDelivered, 0)},
+			want:     1,
+		},
+		{
+			name: "later intermediate receipts follow each other",
+			receipts: []domain.DeliveryReceipt{
+				receipt(1, domain.DeliveryStatusEnroute, 0),
+				receipt(2, domain.DeliveryStatusEnroute, time.Minute),
+			},
+			want: 2,
+		},
+		{
+			name: "later final receipt wins",
+			receipts: []domain.DeliveryReceipt{
+				receipt(1, domain.DeliveryStatusUndelivered, 0),
+				receipt(2, domain.DeliveryStatusExpired, time.Minute),
+			},
+			want: 2,
+		},
+		{
+			name: "out of order final receipt keeps the later one",
+			receipts: []domain.DeliveryReceipt{
+				receipt(1, domain.DeliveryStatusDelivered, time.Minute),
+				receipt(2, domain.DeliveryStatusUndelivered, 0),
+			},
+			want: 1,
+		},
+		{
+			name: "final receipt wins over a later intermediate one",
+			receipts: []domain.DeliveryReceipt{
+				receipt(1, domain.DeliveryStatusDelivered, 0),
+				receipt(2, domain.DeliveryStatusEnroute, time.Minute),
+			},
+			want: 1,
+		},
+		{
+			name: "final receipt wins over an earlier intermediate one",
+			receipts: []domain.DeliveryReceipt{
+				receipt(1, domain.DeliveryStatusEnroute, 0),
+				receipt(2, domain.DeliveryStatusDelivered, time.Minute),
+				receipt(3, domain.DeliveryStatusEnroute, 2*time.Minute),
+			},
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := latestReceipt(tt.receipts); got.ID != tt.want {
+				t.Errorf("latestReceipt() = receipt %d (%s), want receipt %d", got.ID, got.Status, tt.want)
+			}
+		})
+	}
+}
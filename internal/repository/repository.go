@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"insider-challenge/pkg/domain"
 	"insider-challenge/pkg/errors"
@@ -13,11 +18,70 @@ import (
 
 // Repository defines the interface
 type Repository interface {
-	GetUnsentMessages(ctx context.Context, limit int) ([]domain.Message, error)
-	MarkMessageAsSent(ctx context.Context, messageID string) error
-	GetSentMessages(ctx context.Context, page, pageSize int) ([]domain.Message, int64, error)
+	GetSenderState(ctx context.Context) (domain.SenderState, error)
+	SetSenderState(ctx context.Context, state domain.SenderState) error
+	GetUnsentMessages(ctx context.Context, priority domain.Priority, limit int) ([]domain.Message, error)
+	CountPendingMessages(ctx context.Context) (map[domain.Priority]int64, error)
+	MarkMessageAsSent(ctx context.Context, messageID, provider, providerMessageID string, attempt domain.MessageEvent) error
+	RecordSendFailure(ctx context.Context, messageID, reason string, maxAttempts int, attempt domain.MessageEvent) (domain.MessageStatus, error)
+	MarkMessageFailed(ctx context.Context, messageID, reason string) error
+	GetSentMessages(ctx context.Context, filter domain.MessageFilter, offset, limit int) ([]domain.Message, error)
+	GetSentMessagesByCursor(ctx context.Context, filter domain.MessageFilter, cursor domain.MessageCursor, limit int) ([]domain.Message, error)
+	CountSentMessages(ctx context.Context, filter domain.MessageFilter) (int64, error)
+	EstimateSentMessages(ctx context.Context, filter domain.MessageFilter) (int64, error)
+	StreamSentMessages(ctx context.Context, filter domain.MessageFilter, after *domain.MessageCursor, fn func(domain.Message) error) error
+	RecordDeliveryStat(ctx context.Context, stat domain.DeliveryStat) error
+	GetStatsSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsBucket, error)
+	GetLatencyHistogram(ctx context.Context, filter domain.StatsFilter) ([]domain.LatencyBucketCount, error)
+	GetTopFailureReasons(ctx context.Context, filter domain.StatsFilter, limit int) ([]domain.FailureReasonCount, error)
+	CountMessagesByStatus(ctx context.Context, filter domain.StatsFilter) (map[domain.MessageStatus]int64, error)
+	CreateCallbackDelivery(ctx context.Context, delivery *domain.CallbackDelivery) error
+	ClaimDueCallbacks(ctx context.Context, limit int, lease time.Duration) ([]domain.CallbackDelivery, error)
+	RecordCallbackAttempt(ctx context.Context, delivery *domain.CallbackDelivery, attempt domain.CallbackAttempt) error
+	GetCallbackDeliveries(ctx context.Context, messageID string) ([]domain.CallbackDelivery, error)
+	RecordDeliveryReceipt(ctx context.Context, receipt *domain.DeliveryReceipt) (*domain.Message, error)
+	RecordMessageEvent(ctx context.Context, event *domain.MessageEvent) error
+	GetMessageEvents(ctx context.Context, messageID string) ([]domain.MessageEvent, error)
+	UpsertSuppressions(ctx context.Context, entries []domain.Suppression) error
+	GetSuppression(ctx context.Context, phone string) (*domain.Suppression, error)
+	ListSuppressions(ctx context.Context, offset, limit int) ([]domain.Suppression, error)
+	DeleteSuppression(ctx context.Context, phone string) error
+	MarkMessageSuppressed(ctx context.Context, messageID, reason string) error
+	MarkMessageBlocked(ctx context.Context, messageID, reason string) error
+	DeferMessage(ctx context.Context, messageID string, until time.Time, reason string) error
+	UpsertBlackoutDate(ctx context.Context, blackout *domain.BlackoutDate) error
+	ListBlackoutDates(ctx context.Context, from string) ([]domain.BlackoutDate, error)
+	DeleteBlackoutDate(ctx context.Context, date string) error
+	CreateInboundMessage(ctx context.Context, inbound *domain.InboundMessage, optOut *domain.Suppression) (*domain.InboundMessage, error)
+	GetInboundMessages(ctx context.Context, from string, offset, limit int) ([]domain.InboundMessage, error)
+	CreateTemplateVersion(ctx context.Context, template *domain.Template) error
+	GetTemplate(ctx context.Context, id string, version int) (*domain.Template, error)
+	ListTemplates(ctx context.Context) ([]domain.Template, error)
 	CreateMessage(ctx context.Context, message *domain.Message) error
+	MarkMessageAnnounced(ctx context.Context, messageID string) (bool, error)
+	CreateMessageIdempotent(ctx context.Context, message *domain.Message, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error)
+	GetIdempotencyKey(ctx context.Context, clientID, key string) (*domain.IdempotencyKey, error)
+	FindDedupOriginal(ctx context.Context, contentHash string, since time.Time) (*domain.Message, error)
+	FindSentDuplicate(ctx context.Context, contentHash, messageID string, since time.Time) (*domain.Message, error)
+	MarkMessageDuplicate(ctx context.Context, messageID string, originalID uuid.UUID) error
 	GetMessageByID(ctx context.Context, messageID string) (*domain.Message, error)
+	GetMessageByIDUnscoped(ctx context.Context, messageID string) (*domain.Message, error)
+	CancelMessage(ctx context.Context, messageID string) (*domain.Message, error)
+}
+
+// maxErrorLength matches the size of the last_error column
+const maxErrorLength = 255
+
+// truncate shortens the value to at most size bytes. It cuts at a rune boundary, slicing the bytes could split a multi byte
+// character of a provider error or a Turkish reason and postgres rejects the invalid UTF-8 it leaves behind.
+func truncate(value string, size int) string {
+	if len(value) <= size {
+		return value
+	}
+	for size > 0 && !utf8.RuneStart(value[size]) {
+		size--
+	}
+	return value[:size]
 }
 
 // repository implements the repository interface
@@ -30,11 +94,12 @@ func New(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-// GetUnsentMessages retrieves unsent messages from db
-func (r *repository) GetUnsentMessages(ctx context.Context, limit int) ([]domain.Message, error) {
+// GetUnsentMessages retrieves unsent messages of a lane from db, deferred messages are skipped until their not_before
+func (r *repository) GetUnsentMessages(ctx context.Context, priority domain.Priority, limit int) ([]domain.Message, error) {
 	var messages []domain.Message
 	err := r.db.WithContext(ctx).
-		Where("is_sent = ? AND deleted_at IS NULL", false).
+		Where("status = ? AND priority = ? AND deleted_at IS NULL", domain.MessageStatusPending, priority).
+		Where("not_before IS NULL OR not_before <= ?", time.Now()).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&messages).Error
@@ -44,72 +109,255 @@ func (r *repository) GetUnsentMessages(ctx context.Context, limit int) ([]domain
 	return messages, nil
 }
 
-// MarkMessageAsSent marks message as sent in the database
-func (r *repository) MarkMessageAsSent(ctx context.Context, messageID string) error {
+// CountPendingMessages counts the pending messages of every lane, deferred messages included
+func (r *repository) CountPendingMessages(ctx context.Context) (map[domain.Priority]int64, error) {
+	var rows []struct {
+		Priority domain.Priority
+		Count    int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&domain.Message{}).
+		Select("priority, COUNT(*) AS count").
+		Where("status = ? AND deleted_at IS NULL", domain.MessageStatusPending).
+		Group("priority").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "count pending messages")
+	}
+
+	depths := make(map[domain.Priority]int64, len(domain.Priorities))
+	for _, priority := range domain.Priorities {
+		depths[priority] = 0
+	}
+	for _, row := range rows {
+		depths[row.Priority] = row.Count
+	}
+	return depths, nil
+}
+
+// MarkMessageAsSent marks a pending message as sent in the database and records the attempt in its history. The row is
+// locked and must still be pending, a message cancelled, suppressed or failed by a concurrent path is never switched to sent.
+func (r *repository) MarkMessageAsSent(ctx context.Context, messageID, provider, providerMessageID string, attempt domain.MessageEvent) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var message domain.Message
-		if err := tx.Where("id = ? AND deleted_at IS NULL", messageID).First(&message).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", messageID).
+			First(&message).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return errors.Wrap(errors.ErrMessageNotFound, fmt.Sprintf("message ID: %s", messageID))
 			}
 			return errors.Wrap(err, "find message")
 		}
 
+		if message.Status != domain.MessageStatusPending {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s, status: %s", messageID, message.Status))
+		}
+
 		now := time.Now()
 		updates := map[string]interface{}{
-			"is_sent": true,
-			"sent_at": now,
+			"status":              domain.MessageStatusSent,
+			"is_sent":             true,
+			"sent_at":             now,
+			"attempts":            gorm.Expr("attempts + 1"),
+			"last_error":          "",
+			"provider":            provider,
+			"provider_message_id": providerMessageID,
+		}
+
+		result := tx.Model(&message).Where("status = ?", domain.MessageStatusPending).Updates(updates)
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update message")
+		}
+		if result.RowsAffected == 0 {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s", messageID))
+		}
+
+		attempt.MessageID = message.ID
+		transition := domain.MessageEvent{
+			MessageID:  message.ID,
+			Kind:       domain.MessageEventTransition,
+			FromStatus: message.Status,
+			ToStatus:   domain.MessageStatusSent,
+			Provider:   provider,
+		}
+		if err := appendMessageEvents(tx, &attempt, &transition); err != nil {
+			return err
+		}
+
+		message.Provider = provider
+		message.ProviderMessageID = providerMessageID
+		return applyPendingReceipts(tx, &message)
+	})
+}
+
+// RecordSendFailure counts a failed attempt and marks the message failed once maxAttempts is reached
+func (r *repository) RecordSendFailure(ctx context.Context, messageID, reason string, maxAttempts int, attempt domain.MessageEvent) (domain.MessageStatus, error) {
+	var status domain.MessageStatus
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var message domain.Message
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", messageID).
+			First(&message).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.Wrap(errors.ErrMessageNotFound, fmt.Sprintf("message ID: %s", messageID))
+			}
+			return errors.Wrap(err, "find message")
+		}
+
+		if message.Status != domain.MessageStatusPending {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s, status: %s", messageID, message.Status))
+		}
+
+		reason = truncate(reason, maxErrorLength)
+
+		status = message.Status
+		if message.Attempts+1 >= maxAttempts {
+			status = domain.MessageStatusFailed
+		}
+
+		updates := map[string]interface{}{
+			"status":     status,
+			"attempts":   message.Attempts + 1,
+			"last_error": reason,
 		}
 
 		if err := tx.Model(&message).Updates(updates).Error; err != nil {
 			return errors.Wrap(err, "update message")
 		}
 
-		return nil
+		attempt.MessageID = message.ID
+		events := []*domain.MessageEvent{&attempt}
+		if status != message.Status {
+			events = append(events, &domain.MessageEvent{
+				MessageID:  message.ID,
+				Kind:       domain.MessageEventTransition,
+				FromStatus: message.Status,
+				ToStatus:   status,
+				Error:      reason,
+			})
+		}
+		return appendMessageEvents(tx, events...)
 	})
+	if err != nil {
+		return "", err
+	}
+	return status, nil
 }
 
-// GetSentMessages retrieves all sent messages from the db
-func (r *repository) GetSentMessages(ctx context.Context, page, pageSize int) ([]domain.Message, int64, error) {
+// MarkMessageFailed moves a pending message that can never be sent to the failed state without counting an attempt
+func (r *repository) MarkMessageFailed(ctx context.Context, messageID, reason string) error {
+	return r.closePendingMessage(ctx, messageID, domain.MessageStatusFailed, reason, nil)
+}
+
+// GetSentMessages retrieves a page of the sent messages matching the filter from the db
+func (r *repository) GetSentMessages(ctx context.Context, filter domain.MessageFilter, offset, limit int) ([]domain.Message, error) {
 	var messages []domain.Message
-	var total int64
+	err := r.sentMessagesQuery(ctx, filter).
+		Order(sentMessagesOrder(filter)).
+		Offset(offset).
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get sent messages")
+	}
+	return messages, nil
+}
 
-	offset := (page - 1) * pageSize
+// GetSentMessagesByCursor retrieves the sent messages after the cursor position using (sent_at, id) keyset
+func (r *repository) GetSentMessagesByCursor(ctx context.Context, filter domain.MessageFilter, cursor domain.MessageCursor, limit int) ([]domain.Message, error) {
+	// Walking backward reverses the order, the page is flipped back before returning
+	asc := cursor.SortAsc != cursor.Backward
 
-	// Get total count
-	err := r.db.WithContext(ctx).
+	comparison, direction := "<", "DESC"
+	if asc {
+		comparison, direction = ">", "ASC"
+	}
+
+	var messages []domain.Message
+	err := r.sentMessagesQuery(ctx, filter).
+		Where("(sent_at, id) "+comparison+" (?, ?)", cursor.SentAt, cursor.ID).
+		Order("sent_at " + direction + ", id " + direction).
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get sent messages by cursor")
+	}
+
+	if cursor.Backward {
+		slices.Reverse(messages)
+	}
+	return messages, nil
+}
+
+// CountSentMessages counts the sent messages matching the filter exactly
+func (r *repository) CountSentMessages(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	var total int64
+	err := r.sentMessagesQuery(ctx, filter).
 		Model(&domain.Message{}).
-		Where("is_sent = ? AND deleted_at IS NULL", true).
 		Count(&total).Error
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "count sent messages")
+		return 0, errors.Wrap(err, "count sent messages")
 	}
+	return total, nil
+}
 
-	err = r.db.WithContext(ctx).
-		Where("is_sent = ? AND deleted_at IS NULL", true).
-		Order("sent_at DESC").
-		Offset(offset).
-		Limit(pageSize).
-		Find(&messages).Error
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "get sent messages")
+// EstimateSentMessages estimates the sent messages matching the filter from the planner statistics
+func (r *repository) EstimateSentMessages(ctx context.Context, filter domain.MessageFilter) (int64, error) {
+	stmt := r.sentMessagesQuery(ctx, filter).
+		Session(&gorm.Session{DryRun: true}).
+		Model(&domain.Message{}).
+		Select("id").
+		Find(&[]domain.Message{}).
+		Statement
+
+	var plan string
+	if err := r.db.WithContext(ctx).Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Row().Scan(&plan); err != nil {
+		return 0, errors.Wrap(err, "estimate sent messages")
 	}
 
-	return messages, total, nil
+	var explain []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil || len(explain) == 0 {
+		return 0, errors.Wrap(errors.ErrDatabaseOperation, "parse query plan")
+	}
+	return int64(explain[0].Plan.Rows), nil
 }
 
 // CreateMessage create new message in the db
 func (r *repository) CreateMessage(ctx context.Context, message *domain.Message) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(message).Error; err != nil {
-			return errors.Wrap(err, "create message")
-		}
-		return nil
+		return createMessage(tx, message)
+	})
+}
+
+// MarkMessageAnnounced records that the created event of the message was published, it reports false when it already was
+func (r *repository) MarkMessageAnnounced(ctx context.Context, messageID string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&domain.Message{}).
+		Where("id = ? AND announced_at IS NULL", messageID).
+		Update("announced_at", time.Now())
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, "mark message announced")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// createMessage inserts the message with the first entry of its history inside the caller's transaction
+func createMessage(tx *gorm.DB, message *domain.Message) error {
+	if err := tx.Create(message).Error; err != nil {
+		return errors.Wrap(err, "create message")
+	}
+	return appendMessageEvents(tx, &domain.MessageEvent{
+		MessageID: message.ID,
+		Kind:      domain.MessageEventTransition,
+		ToStatus:  message.Status,
 	})
 }
 
 // GetMessageByID retrieves a message by id
-// @info: unused, sample helper function
 func (r *repository) GetMessageByID(ctx context.Context, messageID string) (*domain.Message, error) {
 	var message domain.Message
 	err := r.db.WithContext(ctx).
@@ -123,3 +371,58 @@ func (r *repository) GetMessageByID(ctx context.Context, messageID string) (*dom
 	}
 	return &message, nil
 }
+
+// GetMessageByIDUnscoped retrieves a message by id including cancelled (soft deleted) messages
+func (r *repository) GetMessageByIDUnscoped(ctx context.Context, messageID string) (*domain.Message, error) {
+	var message domain.Message
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id = ?", messageID).
+		First(&message).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(errors.ErrMessageNotFound, fmt.Sprintf("message ID: %s", messageID))
+		}
+		return nil, errors.Wrap(err, "get message by ID")
+	}
+	return &message, nil
+}
+
+// CancelMessage cancels a pending message and soft deletes it
+func (r *repository) CancelMessage(ctx context.Context, messageID string) (*domain.Message, error) {
+	var message domain.Message
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", messageID).
+			First(&message).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.Wrap(errors.ErrMessageNotFound, fmt.Sprintf("message ID: %s", messageID))
+			}
+			return errors.Wrap(err, "find message")
+		}
+
+		if message.Status != domain.MessageStatusPending {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s, status: %s", messageID, message.Status))
+		}
+
+		if err := tx.Model(&message).Update("status", domain.MessageStatusCancelled).Error; err != nil {
+			return errors.Wrap(err, "update message")
+		}
+
+		if err := tx.Delete(&message).Error; err != nil {
+			return errors.Wrap(err, "delete message")
+		}
+
+		return appendMessageEvents(tx, &domain.MessageEvent{
+			MessageID:  message.ID,
+			Kind:       domain.MessageEventAction,
+			Action:     domain.MessageActionCancel,
+			FromStatus: domain.MessageStatusPending,
+			ToStatus:   domain.MessageStatusCancelled,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int
+		want  string
+	}{
+		{value: "short", size: 10, want: "short"},
+		{value: "exact", size: 5, want: "exact"},
+		{value: "too long", size: 3, want: "too"},
+		// Multi byte characters are never cut in half, the stored history stays valid utf-8
+		{value: "çiçek", size: 2, want: "ç"},
+		{value: "çiçek", size: 1, want: ""},
+		{value: "ab🙂", size: 5, want: "ab"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.value, tt.size); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.value, tt.size, got, tt.want)
+		}
+	}
+}
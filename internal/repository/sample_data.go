@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"fmt"
 	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/phone"
+	"insider-challenge/pkg/sms"
 	"time"
 
 	"gorm.io/gorm"
@@ -25,7 +28,7 @@ func InitSampleData(db *gorm.DB) error {
 			CreatedAt: time.Now(),
 		},
 		{
-			To:        "+90555255555",
+			To:        "+905552555555",
 			Content:   "İkinci örnek mesaj",
 			CreatedAt: time.Now().Add(-1 * time.Hour),
 		},
@@ -36,6 +39,20 @@ func InitSampleData(db *gorm.DB) error {
 		},
 	}
 
+	for i := range sampleMessages {
+		// Seeds skip the service validation, parse them so the sender is never handed an invalid number
+		number, err := phone.Parse(sampleMessages[i].To, "")
+		if err != nil {
+			return fmt.Errorf("sample recipient %s: %w", sampleMessages[i].To, err)
+		}
+		sampleMessages[i].To = number.E164
+		sampleMessages[i].Country = number.Region
+
+		analysis := sms.Analyze(sampleMessages[i].Content)
+		sampleMessages[i].Encoding = string(analysis.Encoding)
+		sampleMessages[i].Segments = analysis.Segments
+	}
+
 	if err := db.Create(&sampleMessages).Error; err != nil {
 		return err
 	}
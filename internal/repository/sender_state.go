@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// senderSettingID id of the single sender_settings row
+const senderSettingID = 1
+
+// GetSenderState retrieves the persisted desired sender state, empty when it was never set
+func (r *repository) GetSenderState(ctx context.Context) (domain.SenderState, error) {
+	var setting domain.SenderSetting
+	err := r.db.WithContext(ctx).First(&setting, senderSettingID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "get sender state")
+	}
+	return setting.State, nil
+}
+
+// SetSenderState persists the desired sender state
+func (r *repository) SetSenderState(ctx context.Context, state domain.SenderState) error {
+	setting := domain.SenderSetting{ID: senderSettingID, State: state, UpdatedAt: time.Now()}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"state", "updated_at"}),
+		}).
+		Create(&setting).Error
+	if err != nil {
+		return errors.Wrap(err, "set sender state")
+	}
+	return nil
+}
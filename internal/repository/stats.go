@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// RecordDeliveryStat adds a send attempt to the per minute rollups
+func (r *repository) RecordDeliveryStat(ctx context.Context, stat domain.DeliveryStat) error {
+	bucket := stat.At.UTC().Truncate(time.Minute)
+	latencyMs := stat.Latency.Milliseconds()
+
+	rollup := domain.MessageStatsRollup{
+		Bucket:       bucket,
+		Provider:     stat.Provider,
+		LatencySumMs: latencyMs,
+		LatencyCount: 1,
+	}
+	switch stat.Outcome {
+	case domain.DeliveryOutcomeSent:
+		rollup.SentCount = 1
+	case domain.DeliveryOutcomeFailed:
+		rollup.FailedCount = 1
+	case domain.DeliveryOutcomeDead:
+		rollup.FailedCount = 1
+		rollup.DeadCount = 1
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bucket"}, {Name: "provider"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"sent_count":     gorm.Expr("message_stats_rollups.sent_count + EXCLUDED.sent_count"),
+				"failed_count":   gorm.Expr("message_stats_rollups.failed_count + EXCLUDED.failed_count"),
+				"dead_count":     gorm.Expr("message_stats_rollups.dead_count + EXCLUDED.dead_count"),
+				"latency_sum_ms": gorm.Expr("message_stats_rollups.latency_sum_ms + EXCLUDED.latency_sum_ms"),
+				"latency_count":  gorm.Expr("message_stats_rollups.latency_count + EXCLUDED.latency_count"),
+			}),
+		}).Create(&rollup).Error
+		if err != nil {
+			return errors.Wrap(err, "upsert stats rollup")
+		}
+
+		latency := domain.LatencyRollup{
+			Bucket:       bucket,
+			Provider:     stat.Provider,
+			UpperBoundMs: latencyBucket(latencyMs),
+			Count:        1,
+		}
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bucket"}, {Name: "provider"}, {Name: "upper_bound_ms"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count": gorm.Expr("latency_rollups.count + EXCLUDED.count"),
+			}),
+		}).Create(&latency).Error
+		if err != nil {
+			return errors.Wrap(err, "upsert latency rollup")
+		}
+
+		if stat.Reason == "" {
+			return nil
+		}
+
+		failure := domain.FailureRollup{
+			Bucket:   bucket,
+			Provider: stat.Provider,
+			Reason:   truncate(stat.Reason, 100),
+			Count:    1,
+		}
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "bucket"}, {Name: "provider"}, {Name: "reason"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count": gorm.Expr("failure_rollups.count + EXCLUDED.count"),
+			}),
+		}).Create(&failure).Error
+		if err != nil {
+			return errors.Wrap(err, "upsert failure rollup")
+		}
+
+		return nil
+	})
+}
+
+// GetStatsSeries retrieves the delivery counters grouped by the filter interval
+func (r *repository) GetStatsSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsBucket, error) {
+	var series []domain.StatsBucket
+	err := statsQuery(r.db.WithContext(ctx), filter).
+		Model(&domain.MessageStatsRollup{}).
+		Select(`date_trunc(?, bucket) AS bucket,
+			SUM(sent_count) AS sent,
+			SUM(failed_count) AS failed,
+			SUM(dead_count) AS dead,
+			SUM(latency_sum_ms) AS latency_sum_ms,
+			SUM(latency_count) AS latency_count`, filter.Interval).
+		Group("1").
+		Order("1").
+		Scan(&series).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get stats series")
+	}
+	return series, nil
+}
+
+// GetLatencyHistogram retrieves the merged latency histogram of the filter range
+func (r *repository) GetLatencyHistogram(ctx context.Context, filter domain.StatsFilter) ([]domain.LatencyBucketCount, error) {
+	var histogram []domain.LatencyBucketCount
+	err := statsQuery(r.db.WithContext(ctx), filter).
+		Model(&domain.LatencyRollup{}).
+		Select("upper_bound_ms, SUM(count) AS count").
+		Group("upper_bound_ms").
+		Order("upper_bound_ms").
+		Scan(&histogram).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get latency histogram")
+	}
+	return histogram, nil
+}
+
+// GetTopFailureReasons retrieves the most frequent failure reasons of the filter range
+func (r *repository) GetTopFailureReasons(ctx context.Context, filter domain.StatsFilter, limit int) ([]domain.FailureReasonCount, error) {
+	var reasons []domain.FailureReasonCount
+	err := statsQuery(r.db.WithContext(ctx), filter).
+		Model(&domain.FailureRollup{}).
+		Select("reason, SUM(count) AS count").
+		Group("reason").
+		Order("count DESC, reason").
+		Limit(limit).
+		Scan(&reasons).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "get top failure reasons")
+	}
+	return reasons, nil
+}
+
+// CountMessagesByStatus counts the messages created in the filter range by their current status, cancelled messages included
+func (r *repository) CountMessagesByStatus(ctx context.Context, filter domain.StatsFilter) (map[domain.MessageStatus]int64, error) {
+	var rows []struct {
+		Status domain.MessageStatus
+		Count  int64
+	}
+	err := statsQuery(r.db.WithContext(ctx), filter).
+		Model(&domain.MessageStatusRollup{}).
+		Select("status, SUM(count) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "count messages by status")
+	}
+
+	counts := make(map[domain.MessageStatus]int64, len(domain.MessageStatuses))
+	for _, status := range domain.MessageStatuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// statsQuery restricts a rollup query to the filter range and provider
+func statsQuery(db *gorm.DB, filter domain.StatsFilter) *gorm.DB {
+	query := db.Where("bucket >= ? AND bucket < ?", filter.From, filter.To)
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	return query
+}
+
+// latencyBucket returns the histogram bucket of the latency, the last bucket catches everything above
+func latencyBucket(latencyMs int64) int {
+	for _, upperBound := range domain.LatencyBucketsMs {
+		if latencyMs <= int64(upperBound) {
+			return upperBound
+		}
+	}
+	return domain.LatencyBucketsMs[len(domain.LatencyBucketsMs)-1]
+}
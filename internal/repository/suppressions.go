@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// suppressionBatchSize rows per insert statement of a bulk import
+const suppressionBatchSize = 500
+
+// UpsertSuppressions adds the entries to the suppression list, existing entries are replaced
+func (r *repository) UpsertSuppressions(ctx context.Context, entries []domain.Suppression) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "phone"}},
+			DoUpdates: clause.AssignmentColumns([]string{"reason", "source", "expires_at", "updated_at"}),
+		}).
+		CreateInBatches(entries, suppressionBatchSize).Error
+	if err != nil {
+		return errors.Wrap(err, "upsert suppressions")
+	}
+	return nil
+}
+
+// GetSuppression retrieves the active suppression of a phone number
+func (r *repository) GetSuppression(ctx context.Context, phone string) (*domain.Suppression, error) {
+	var suppression domain.Suppression
+	err := r.db.WithContext(ctx).
+		Where("phone = ? AND (expires_at IS NULL OR expires_at > ?)", phone, time.Now()).
+		First(&suppression).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(errors.ErrSuppressionNotFound, fmt.Sprintf("phone: %s", phone))
+		}
+		return nil, errors.Wrap(err, "get suppression")
+	}
+	return &suppression, nil
+}
+
+// ListSuppressions retrieves a page of the active suppressions, newest first
+func (r *repository) ListSuppressions(ctx context.Context, offset, limit int) ([]domain.Suppression, error) {
+	var suppressions []domain.Suppression
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("created_at DESC, phone ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&suppressions).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list suppressions")
+	}
+	return suppressions, nil
+}
+
+// DeleteSuppression removes a phone number from the suppression list
+func (r *repository) DeleteSuppression(ctx context.Context, phone string) error {
+	result := r.db.WithContext(ctx).Where("phone = ?", phone).Delete(&domain.Suppression{})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "delete suppression")
+	}
+	if result.RowsAffected == 0 {
+		return errors.Wrap(errors.ErrSuppressionNotFound, fmt.Sprintf("phone: %s", phone))
+	}
+	return nil
+}
+
+// MarkMessageSuppressed moves a pending message to the suppressed terminal state
+func (r *repository) MarkMessageSuppressed(ctx context.Context, messageID, reason string) error {
+	return r.closePendingMessage(ctx, messageID, domain.MessageStatusSuppressed, reason, nil)
+}
+
+// closePendingMessage moves a pending message to a terminal state it is never sent from, extra columns are updated with it
+func (r *repository) closePendingMessage(ctx context.Context, messageID string, status domain.MessageStatus, reason string, extra map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var message domain.Message
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NULL", messageID).
+			First(&message).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.Wrap(errors.ErrMessageNotFound, fmt.Sprintf("message ID: %s", messageID))
+			}
+			return errors.Wrap(err, "find message")
+		}
+
+		if message.Status != domain.MessageStatusPending {
+			return errors.Wrap(errors.ErrMessageNotPending, fmt.Sprintf("message ID: %s, status: %s", messageID, message.Status))
+		}
+
+		reason = truncate(reason, maxErrorLength)
+		updates := map[string]interface{}{
+			"status":     status,
+			"last_error": reason,
+		}
+		for column, value := range extra {
+			updates[column] = value
+		}
+		if err := tx.Model(&message).Updates(updates).Error; err != nil {
+			return errors.Wrap(err, "update message")
+		}
+
+		return appendMessageEvents(tx, &domain.MessageEvent{
+			MessageID:  message.ID,
+			Kind:       domain.MessageEventTransition,
+			FromStatus: domain.MessageStatusPending,
+			ToStatus:   status,
+			Detail:     reason,
+		})
+	})
+}
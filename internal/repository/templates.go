@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// CreateTemplateVersion stores the template as the next version of its id
+func (r *repository) CreateTemplateVersion(ctx context.Context, template *domain.Template) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Concurrent edits of the same template would otherwise pick the same version
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "template:"+template.ID).Error; err != nil {
+			return errors.Wrap(err, "lock template")
+		}
+
+		var latest int
+		err := tx.Model(&domain.Template{}).
+			Where("id = ?", template.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error
+		if err != nil {
+			return errors.Wrap(err, "find latest template version")
+		}
+
+		template.Version = latest + 1
+		if err := tx.Create(template).Error; err != nil {
+			return errors.Wrap(err, "create template")
+		}
+		return nil
+	})
+}
+
+// GetTemplate retrieves a version of a template, version 0 is the latest
+func (r *repository) GetTemplate(ctx context.Context, id string, version int) (*domain.Template, error) {
+	query := r.db.WithContext(ctx).Where("id = ?", id)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	var template domain.Template
+	if err := query.Order("version DESC").First(&template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(errors.ErrTemplateNotFound, fmt.Sprintf("template: %s, version: %d", id, version))
+		}
+		return nil, errors.Wrap(err, "get template")
+	}
+	return &template, nil
+}
+
+// ListTemplates retrieves the latest version of every template
+func (r *repository) ListTemplates(ctx context.Context) ([]domain.Template, error) {
+	latest := r.db.Model(&domain.Template{}).Select("id, MAX(version)").Group("id")
+
+	var templates []domain.Template
+	err := r.db.WithContext(ctx).
+		Where("(id, version) IN (?)", latest).
+		Order("id ASC").
+		Find(&templates).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list templates")
+	}
+	return templates, nil
+}
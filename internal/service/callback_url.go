@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"insider-challenge/pkg/errors"
+)
+
+// sharedAddressSpace carrier grade NAT range (RFC 6598), not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// validateCallbackURL rejects callback urls that are not absolute http(s) urls or that name a host of the internal network.
+// Host names are resolved again on every delivery, the dialer of the callback client checks the resolved addresses.
+func validateCallbackURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return &errors.ValidationError{Field: "callback_url", Code: "invalid_url", Message: "must be an absolute http(s) url"}
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &errors.ValidationError{Field: "callback_url", Code: "forbidden_host", Message: "must not point to localhost"}
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return &errors.ValidationError{Field: "callback_url", Code: "forbidden_host", Message: "must not point to a private, loopback or link-local address"}
+	}
+	return nil
+}
+
+// isPublicIP reports whether the address is routable on the internet, callbacks are never sent anywhere else
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// newCallbackClient creates the http client of the callback dispatcher. Its dialer refuses addresses of the internal network
+// after resolution, so a host name resolving to one cannot be used to reach it, and redirects are not followed since they
+// could lead there too. Proxies from the environment are ignored as the dialer would only see the proxy address.
+func newCallbackClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("callback destination %s is not a public address", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 4,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	stderrors "errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/errors"
+)
+
+func TestValidateCallbackURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantCode string
+	}{
+		{url: "https://hooks.example.com/sms", wantCode: ""},
+		{url: "http://203.0.113.10:8080/callback", wantCode: ""},
+		{url: "ftp://hooks.example.com/sms", wantCode: "invalid_url"},
+		{url: "/relative/path", wantCode: "invalid_url"},
+		{url: "https://", wantCode: "invalid_url"},
+		{url: "http://localhost:8080/", wantCode: "forbidden_host"},
+		{url: "http://LOCALHOST./", wantCode: "forbidden_host"},
+		{url: "http://api.localhost/", wantCode: "forbidden_host"},
+		{url: "http://127.0.0.1/", wantCode: "forbidden_host"},
+		{url: "http://10.1.2.3/", wantCode: "forbidden_host"},
+		{url: "http://169.254.169.254/latest/meta-data", wantCode: "forbidden_host"},
+		{url: "http://100.64.0.1/", wantCode: "forbidden_host"},
+		{url: "http://[::1]:8080/", wantCode: "forbidden_host"},
+		{url: "http://[fd00::1]/", wantCode: "forbidden_host"},
+		{url: "http://0.0.0.0/", wantCode: "forbidden_host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := validateCallbackURL(tt.url)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("validateCallbackURL() error = %v, want none", err)
+				}
+				return
+			}
+
+			var validationErr *errors.ValidationError
+			if !stderrors.As(err, &validationErr) || validationErr.Code != tt.wantCode {
+				t.Errorf("validateCallbackURL() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":         true,
+		"203.0.113.10":    true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"192.168.1.1":     false,
+		"172.16.0.1":      false,
+		"100.127.255.255": false,
+		"100.128.0.1":     true,
+		"224.0.0.1":       false,
+		"fe80::1":         false,
+		"::":              false,
+	}
+
+	for addr, want := range tests {
+		if got := isPublicIP(net.ParseIP(addr)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+// The url check only sees literal addresses, the dialer has to refuse a host name that resolves to the internal network
+func TestCallbackClientRefusesInternalAddresses(t *testing.T) {
+	reached := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	client := newCallbackClient(time.Second)
+
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		resp, err := client.Get("http://" + net.JoinHostPort(host, port) + "/callback")
+		if err == nil {
+			resp.Body.Close()
+			t.Fatalf("GET via %s succeeded, want the dialer to refuse it", host)
+		}
+		if !strings.Contains(err.Error(), "not a public address") {
+			t.Errorf("GET via %s error = %v, want a refused destination", host, err)
+		}
+	}
+	if reached {
+		t.Error("callback client reached the loopback server")
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"insider-challenge/internal/repository"
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// Callback request headers
+const (
+	CallbackSignatureHeader = "X-Callback-Signature"
+	CallbackTimestampHeader = "X-Callback-Timestamp"
+	CallbackIDHeader        = "X-Callback-ID"
+)
+
+// callbackClaimRounds number of callbacks claimed per worker and poll, the claim lease covers all of them
+const callbackClaimRounds = 4
+
+// CallbackPayload is the body of a status callback
+type CallbackPayload struct {
+	Event             domain.LifecycleEventType `json:"event"`
+	MessageID         string                    `json:"message_id"`
+	To                string                    `json:"to"`
+	Status            domain.MessageStatus      `json:"status"`
+	Provider          string                    `json:"provider,omitempty"`
+	ProviderMessageID string                    `json:"provider_message_id,omitempty"`
+	Error             string                    `json:"error,omitempty"`
+	OccurredAt        time.Time                 `json:"occurred_at"`
+}
+
+// CallbackDispatcher delivers queued status callbacks, isolated from the message sender so slow callbacks never delay sends
+type CallbackDispatcher struct {
+	repo        repository.Repository
+	cfg         *config.Config
+	client      *http.Client
+	stopChan    chan struct{}
+	doneChan    chan struct{}
+	isRunning   bool
+	runningLock sync.Mutex
+
+	// pollInterval how often due callbacks are claimed
+	pollInterval time.Duration
+
+	// claimLease how long a claimed callback is hidden from other replicas
+	claimLease time.Duration
+
+	// baseBackoff delay before the first retry, doubled on every attempt
+	baseBackoff time.Duration
+
+	// maxBackoff upper bound of the retry delay
+	maxBackoff time.Duration
+}
+
+// NewCallbackDispatcher creates a new callback dispatcher instance
+func NewCallbackDispatcher(repo repository.Repository, cfg *config.Config) *CallbackDispatcher {
+	return &CallbackDispatcher{
+		repo:         repo,
+		cfg:          cfg,
+		client:       newCallbackClient(cfg.CallbackTimeout),
+		pollInterval: 2 * time.Second,
+		claimLease:   cfg.CallbackTimeout * (callbackClaimRounds + 1),
+		baseBackoff:  10 * time.Second,
+		maxBackoff:   1 * time.Hour,
+	}
+}
+
+// Start starts polling for due callbacks
+func (cd *CallbackDispatcher) Start() {
+	cd.runningLock.Lock()
+	if cd.isRunning {
+		cd.runningLock.Unlock()
+		return
+	}
+	cd.isRunning = true
+	cd.stopChan = make(chan struct{})
+	cd.doneChan = make(chan struct{})
+	cd.runningLock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(cd.pollInterval)
+		defer ticker.Stop()
+		defer close(cd.doneChan)
+
+		for {
+			select {
+			case <-ticker.C:
+				cd.dispatch()
+			case <-cd.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the dispatcher after the in flight callbacks finished
+func (cd *CallbackDispatcher) Stop() {
+	cd.runningLock.Lock()
+	if !cd.isRunning {
+		cd.runningLock.Unlock()
+		return
+	}
+
+	close(cd.stopChan)
+	cd.isRunning = false
+	cd.runningLock.Unlock()
+
+	<-cd.doneChan
+}
+
+// Enqueue queues a callback for the event when the message has a callback url
+func (cd *CallbackDispatcher) Enqueue(ctx context.Context, msg domain.Message, event domain.LifecycleEvent, providerMessageID string) {
+	if msg.CallbackURL == "" {
+		return
+	}
+	switch event.Type {
+	case domain.LifecycleEventSent, domain.LifecycleEventDead, domain.LifecycleEventSuppressed, domain.LifecycleEventBlocked,
+		domain.LifecycleEventDuplicate:
+	default:
+		return
+	}
+
+	payload, err := json.Marshal(CallbackPayload{
+		Event:             event.Type,
+		MessageID:         msg.ID.String(),
+		To:                msg.To,
+		Status:            event.Status,
+		Provider:          event.Provider,
+		ProviderMessageID: providerMessageID,
+		Error:             event.Error,
+		OccurredAt:        event.OccurredAt,
+	})
+	if err != nil {
+		log.Printf("Failed to marshal callback of message %s: %v", msg.ID, err)
+		return
+	}
+
+	delivery := &domain.CallbackDelivery{
+		MessageID:     msg.ID,
+		URL:           msg.CallbackURL,
+		Event:         event.Type,
+		Payload:       string(payload),
+		Status:        domain.CallbackStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := cd.repo.CreateCallbackDelivery(ctx, delivery); err != nil {
+		log.Printf("Failed to queue %s callback of message %s: %v", event.Type, msg.ID, err)
+	}
+}
+
+// dispatch claims the due callbacks and delivers them concurrently
+func (cd *CallbackDispatcher) dispatch() {
+	ctx, cancel := context.WithTimeout(context.Background(), cd.claimLease)
+	defer cancel()
+
+	deliveries, err := cd.repo.ClaimDueCallbacks(ctx, cd.cfg.CallbackWorkers*callbackClaimRounds, cd.claimLease)
+	if err != nil {
+		log.Printf("Failed to claim due callbacks: %v", err)
+		return
+	}
+
+	jobs := make(chan *domain.CallbackDelivery)
+	var wg sync.WaitGroup
+	for i := 0; i < cd.cfg.CallbackWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for delivery := range jobs {
+				cd.deliver(ctx, delivery)
+			}
+		}()
+	}
+
+	for i := range deliveries {
+		jobs <- &deliveries[i]
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// deliver posts a single callback and schedules a retry when it fails
+func (cd *CallbackDispatcher) deliver(ctx context.Context, delivery *domain.CallbackDelivery) {
+	start := time.Now()
+	statusCode, err := cd.post(ctx, delivery)
+
+	delivery.Attempts++
+	attempt := domain.CallbackAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts,
+		StatusCode:  statusCode,
+		LatencyMs:   time.Since(start).Milliseconds(),
+		AttemptedAt: start,
+	}
+
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = domain.CallbackStatusDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= cd.cfg.CallbackMaxAttempts:
+		attempt.Error = err.Error()
+		delivery.Status = domain.CallbackStatusFailed
+		log.Printf("Callback %s of message %s failed permanently: %v", delivery.ID, delivery.MessageID, err)
+	default:
+		attempt.Error = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(cd.backoff(delivery.Attempts))
+	}
+
+	if err := cd.repo.RecordCallbackAttempt(ctx, delivery, attempt); err != nil {
+		log.Printf("Failed to record callback attempt %s: %v", delivery.ID, err)
+	}
+}
+
+// post sends the signed callback, any 2xx response counts as delivered
+func (cd *CallbackDispatcher) post(ctx context.Context, delivery *domain.CallbackDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "create callback request")
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(CallbackIDHeader, delivery.ID.String())
+	req.Header.Set(CallbackTimestampHeader, timestamp)
+	if cd.cfg.CallbackSigningSecret != "" {
+		req.Header.Set(CallbackSignatureHeader, "sha256="+SignCallback(cd.cfg.CallbackSigningSecret, timestamp, delivery.Payload))
+	}
+
+	resp, err := cd.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "send callback")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt
+func (cd *CallbackDispatcher) backoff(attempts int) time.Duration {
+	delay := cd.baseBackoff
+	for i := 1; i < attempts && delay < cd.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > cd.maxBackoff {
+		delay = cd.maxBackoff
+	}
+	return delay
+}
+
+// SignCallback computes the hex hmac-sha256 signature of "<timestamp>.<payload>"
+func SignCallback(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
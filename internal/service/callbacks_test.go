@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCallbackBackoff(t *testing.T) {
+	cd := &CallbackDispatcher{baseBackoff: 10 * time.Second, maxBackoff: time.Hour}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 10 * time.Second},
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 4, want: 80 * time.Second},
+		{attempts: 9, want: 2560 * time.Second},
+		{attempts: 10, want: time.Hour},
+		{attempts: 1000, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := cd.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestSignCallback(t *testing.T) {
+	// Computed independently, receivers verify the signature with their own hmac implementation
+	const want = "49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	if got := SignCallback("secret", "1700000000", `{"a":1}`); got != want {
+		t.Errorf("SignCallback() = %s, want %s", got, want)
+	}
+
+	// The timestamp is signed too, a captured payload cannot be replayed under a new one
+	if SignCallback("secret", "1700000001", `{"a":1}`) == want {
+		t.Error("SignCallback() ignores the timestamp")
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// contentHash fingerprints the recipient and content of a message for deduplication
+func contentHash(to, content string) string {
+	sum := sha256.Sum256([]byte(to + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// checkDuplicate turns the new message into a duplicate when the same content was accepted for the recipient within
+// DEDUP_WINDOW. Redis answers for new content, a hit is confirmed in postgres since the original may never have been stored.
+// claimed reports whether the message holds the redis claim of its content, it has to be released if the message is not stored.
+func (s *Service) checkDuplicate(ctx context.Context, msg *domain.Message) (claimed bool, err error) {
+	if s.cfg.DedupWindow <= 0 || msg.ContentHash == "" {
+		return false, nil
+	}
+
+	claimed, err = config.ClaimDedupKey(ctx, msg.ContentHash, s.cfg.DedupWindow)
+	if err != nil {
+		// Postgres stays the source of truth while redis is unavailable
+		log.Printf("Failed to claim dedup key: %v", err)
+		claimed = false
+	}
+	if claimed {
+		return true, nil
+	}
+
+	original, err := s.repo.FindDedupOriginal(ctx, msg.ContentHash, time.Now().Add(-s.cfg.DedupWindow))
+	if err != nil {
+		return false, errors.Wrap(err, "check duplicate")
+	}
+	if original == nil {
+		return false, nil
+	}
+
+	msg.Status = domain.MessageStatusDuplicate
+	msg.DuplicateOf = &original.ID
+	msg.LastError = "duplicate of " + original.ID.String()
+	return false, nil
+}
+
+// releaseDuplicateClaim releases the redis claim of a message that was not stored, otherwise the next message with the
+// same content would be checked against an original that does not exist
+func (s *Service) releaseDuplicateClaim(ctx context.Context, msg *domain.Message) {
+	// The claim is released even when the insert failed because the request timed out
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.httpTimeout)
+	defer cancel()
+
+	if err := config.ReleaseDedupKey(ctx, msg.ContentHash); err != nil {
+		// Postgres finds no original for a stale claim, the key only costs a lookup until it expires
+		log.Printf("Failed to release dedup key: %v", err)
+	}
+}
+
+// sentDuplicate returns the message with the same content sent to the recipient within DEDUP_WINDOW, nil when there is none.
+// It catches duplicates that raced past the ingestion check or were accepted while redis was unavailable. Like the
+// ingestion check, redis answers when the message is the first to send the content, a claim held by another message is
+// confirmed in postgres since that message may never have been sent.
+func (ms *MessageSender) sentDuplicate(ctx context.Context, msg domain.Message) (*domain.Message, error) {
+	if ms.cfg.DedupWindow <= 0 || msg.ContentHash == "" {
+		return nil, nil
+	}
+
+	owner, err := config.ClaimDedupSend(ctx, msg.ContentHash, msg.ID.String(), ms.cfg.DedupWindow)
+	if err != nil {
+		log.Printf("Failed to claim dedup send key: %v", err)
+	}
+	// A retried message finds its own claim
+	if err == nil && (owner == "" || owner == msg.ID.String()) {
+		return nil, nil
+	}
+
+	original, err := ms.repo.FindSentDuplicate(ctx, msg.ContentHash, msg.ID.String(), time.Now().Add(-ms.cfg.DedupWindow))
+	if err != nil {
+		return nil, errors.Wrap(err, "check duplicate")
+	}
+	return original, nil
+}
+
+// markDuplicate moves the message to the duplicate terminal state
+func (ms *MessageSender) markDuplicate(ctx context.Context, msg domain.Message, original domain.Message) {
+	if err := ms.repo.MarkMessageDuplicate(ctx, msg.ID.String(), original.ID); err != nil {
+		log.Printf("Failed to mark message %s as duplicate: %v", msg.ID, err)
+		return
+	}
+
+	event := domain.LifecycleEvent{
+		Type:       domain.LifecycleEventDuplicate,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusDuplicate,
+		Error:      "duplicate of " + original.ID.String(),
+		OccurredAt: time.Now(),
+	}
+	ms.events.Publish(event)
+	ms.callbacks.Enqueue(ctx, msg, event, "")
+}
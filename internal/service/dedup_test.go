@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+)
+
+// withUnavailableRedis points the redis client at a closed port for the test, so the checks fall back to postgres
+func withUnavailableRedis(t *testing.T) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	previous := config.RedisClient
+	config.RedisClient = redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() {
+		config.RedisClient.Close()
+		config.RedisClient = previous
+	})
+}
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name      string
+		to1, to2  string
+		content1  string
+		content2  string
+		wantEqual bool
+	}{
+		{name: "same message", to1: "+905551234567", content1: "Hello", to2: "+905551234567", content2: "Hello", wantEqual: true},
+		{name: "other recipient", to1: "+905551234567", content1: "Hello", to2: "+905551234568", content2: "Hello"},
+		{name: "other content", to1: "+905551234567", content1: "Hello", to2: "+905551234567", content2: "Hello!"},
+		{name: "boundary between recipient and content", to1: "+9055512345671", content1: "23", to2: "+905551234567", content2: "123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if equal := contentHash(tt.to1, tt.content1) == contentHash(tt.to2, tt.content2); equal != tt.wantEqual {
+				t.Errorf("contentHash() equal = %v, want %v", equal, tt.wantEqual)
+			}
+		})
+	}
+}
+
+// checkLookup fails the test unless the repository was asked exactly once, for the hash and message within the window
+func checkLookup(t *testing.T, repo *fakeRepository, hash, messageID string, window time.Duration, before time.Time) {
+	t.Helper()
+	if len(repo.dedupLookups) != 1 {
+		t.Fatalf("duplicate lookups = %d, want 1", len(repo.dedupLookups))
+	}
+
+	lookup := repo.dedupLookups[0]
+	if lookup.contentHash != hash || lookup.messageID != messageID {
+		t.Errorf("duplicate lookup = hash %s of message %q, want hash %s of message %q", lookup.contentHash, lookup.messageID, hash, messageID)
+	}
+	if earliest := before.Add(-window); lookup.since.Before(earliest) || lookup.since.After(time.Now().Add(-window)) {
+		t.Errorf("duplicate lookup since = %s, want %s ago", lookup.since, window)
+	}
+}
+
+func TestCheckDuplicate(t *testing.T) {
+	withUnavailableRedis(t)
+
+	hash := contentHash("+905551234567", "Hello")
+	original := &domain.Message{ID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		window    time.Duration
+		hash      string
+		original  *domain.Message
+		lookup    bool
+		duplicate bool
+	}{
+		{name: "disabled", window: 0, hash: hash, original: original},
+		{name: "resend without hash", window: time.Hour, hash: "", original: original},
+		{name: "new content", window: time.Hour, hash: hash, lookup: true},
+		{name: "original within the window", window: time.Hour, hash: hash, original: original, lookup: true, duplicate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{dedupOriginal: tt.original}
+			s := &Service{repo: repo, cfg: &config.Config{DedupWindow: tt.window}}
+			msg := &domain.Message{ContentHash: tt.hash, Status: domain.MessageStatusPending}
+
+			before := time.Now()
+			claimed, err := s.checkDuplicate(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("checkDuplicate() error = %v", err)
+			}
+			// Without redis nothing is claimed, so nothing has to be released
+			if claimed {
+				t.Error("checkDuplicate() claimed the content without redis")
+			}
+
+			if tt.lookup {
+				checkLookup(t, repo, tt.hash, "", tt.window, before)
+			} else if len(repo.dedupLookups) != 0 {
+				t.Errorf("duplicate lookups = %d, want none", len(repo.dedupLookups))
+			}
+
+			if !tt.duplicate {
+				if msg.Status != domain.MessageStatusPending || msg.DuplicateOf != nil {
+					t.Errorf("checkDuplicate() marked the message duplicate of %v", msg.DuplicateOf)
+				}
+				return
+			}
+			if msg.Status != domain.MessageStatusDuplicate || msg.DuplicateOf == nil || *msg.DuplicateOf != tt.original.ID {
+				t.Errorf("checkDuplicate() = %s of %v, want duplicate of %v", msg.Status, msg.DuplicateOf, tt.original.ID)
+			}
+		})
+	}
+}
+
+func TestSentDuplicate(t *testing.T) {
+	withUnavailableRedis(t)
+
+	hash := contentHash("+905551234567", "Hello")
+	msg := domain.Message{ID: uuid.New(), ContentHash: hash, Status: domain.MessageStatusPending}
+	sent := &domain.Message{ID: uuid.New(), ContentHash: hash, Status: domain.MessageStatusSent}
+
+	tests := []struct {
+		name     string
+		window   time.Duration
+		original *domain.Message
+		lookup   bool
+	}{
+		{name: "disabled", window: 0, original: sent},
+		{name: "nothing sent", window: time.Hour, lookup: true},
+		{name: "sent within the window", window: time.Hour, original: sent, lookup: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{sentDuplicate: tt.original}
+			ms := &MessageSender{repo: repo, cfg: &config.Config{DedupWindow: tt.window}}
+
+			before := time.Now()
+			original, err := ms.sentDuplicate(context.Background(), msg)
+			if err != nil {
+				t.Fatalf("sentDuplicate() error = %v", err)
+			}
+
+			if !tt.lookup {
+				if original != nil || len(repo.dedupLookups) != 0 {
+					t.Errorf("sentDuplicate() = %v after %d lookups, want no lookup", original, len(repo.dedupLookups))
+				}
+				return
+			}
+			// Without redis postgres answers, the message itself is excluded by its id
+			checkLookup(t, repo, hash, msg.ID.String(), tt.window, before)
+			if original != tt.original {
+				t.Errorf("sentDuplicate() = %v, want %v", original, tt.original)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"fmt"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/sms"
+)
+
+// AnalyzeContent returns the encoding, character count and segment count of the content
+func (s *Service) AnalyzeContent(content string) sms.Analysis {
+	return sms.Analyze(content)
+}
+
+// prepareContent transliterates the content when requested and applies SEGMENT_POLICY when it does not fit a single segment
+func (s *Service) prepareContent(content, language string, transliterate bool) (string, sms.Analysis, error) {
+	if transliterate {
+		content = sms.Transliterate(content, language)
+	}
+
+	analysis := sms.Analyze(content)
+	if analysis.Segments <= 1 {
+		return content, analysis, nil
+	}
+
+	switch domain.SegmentPolicy(s.cfg.SegmentPolicy) {
+	case domain.SegmentPolicyReject:
+		return "", analysis, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf(
+			"content needs %d %s segments, a single segment holds %d characters", analysis.Segments, analysis.Encoding, singleSegment(analysis.Encoding)))
+	case domain.SegmentPolicyTransliterate:
+		if !transliterate {
+			content = sms.Transliterate(content, language)
+			analysis = sms.Analyze(content)
+		}
+	}
+	return content, analysis, nil
+}
+
+// singleSegment capacity of a single segment in the encoding
+func singleSegment(encoding sms.Encoding) int {
+	if encoding == sms.EncodingUCS2 {
+		return sms.UCS2SingleSegment
+	}
+	return sms.GSM7SingleSegment
+}
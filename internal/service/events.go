@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// eventSubscriberBuffer events buffered per subscriber before it is considered too slow
+const eventSubscriberBuffer = 256
+
+// EventSubscription receives the live lifecycle events of every replica
+type EventSubscription struct {
+	Events <-chan domain.LifecycleEvent
+	events chan domain.LifecycleEvent
+}
+
+// EventHub publishes lifecycle events through redis and fans them out to the local subscribers
+type EventHub struct {
+	replaySize     int
+	requestTimeout time.Duration
+
+	subscribersLock sync.Mutex
+	subscribers     map[*EventSubscription]struct{}
+}
+
+// NewEventHub creates a new event hub instance
+func NewEventHub(cfg *config.Config) *EventHub {
+	return &EventHub{
+		replaySize:     cfg.EventsReplaySize,
+		requestTimeout: 2 * time.Second,
+		subscribers:    make(map[*EventSubscription]struct{}),
+	}
+}
+
+// Publish broadcasts the event to every replica, failures are logged and never block the caller's work
+func (eh *EventHub) Publish(event domain.LifecycleEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to marshal %s event of message %s: %v", event.Type, event.MessageID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eh.requestTimeout)
+	defer cancel()
+
+	if _, err := config.PublishEvent(ctx, data, eh.replaySize); err != nil {
+		log.Printf("Failed to publish %s event of message %s: %v", event.Type, event.MessageID, err)
+	}
+}
+
+// Subscribe registers a local subscriber for live events, it must be released with Unsubscribe
+func (eh *EventHub) Subscribe() *EventSubscription {
+	events := make(chan domain.LifecycleEvent, eventSubscriberBuffer)
+	sub := &EventSubscription{Events: events, events: events}
+
+	eh.subscribersLock.Lock()
+	eh.subscribers[sub] = struct{}{}
+	eh.subscribersLock.Unlock()
+
+	return sub
+}
+
+// Unsubscribe releases the subscriber and closes its channel
+func (eh *EventHub) Unsubscribe(sub *EventSubscription) {
+	eh.subscribersLock.Lock()
+	defer eh.subscribersLock.Unlock()
+
+	if _, ok := eh.subscribers[sub]; ok {
+		delete(eh.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Replay retrieves the buffered events published after lastID
+func (eh *EventHub) Replay(ctx context.Context, lastID string) ([]domain.LifecycleEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, eh.requestTimeout)
+	defer cancel()
+
+	entries, err := config.GetEventsAfter(ctx, lastID, int64(eh.replaySize))
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrStateStore, err.Error())
+	}
+
+	events := make([]domain.LifecycleEvent, 0, len(entries))
+	for _, entry := range entries {
+		var event domain.LifecycleEvent
+		if err := json.Unmarshal([]byte(entry.Data), &event); err != nil {
+			continue
+		}
+		event.ID = entry.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Run fans out the events published by every replica to the local subscribers until ctx is done
+func (eh *EventHub) Run(ctx context.Context) {
+	pubsub := config.SubscribeEvents(ctx)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			id, data, found := strings.Cut(msg.Payload, " ")
+			if !found {
+				continue
+			}
+
+			var event domain.LifecycleEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				log.Printf("Ignoring malformed lifecycle event: %v", err)
+				continue
+			}
+			event.ID = id
+			eh.broadcast(event)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// broadcast hands the event to every local subscriber, subscribers that fall behind are dropped
+func (eh *EventHub) broadcast(event domain.LifecycleEvent) {
+	eh.subscribersLock.Lock()
+	defer eh.subscribersLock.Unlock()
+
+	for sub := range eh.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			// Closing the channel ends the stream, the client resumes with Last-Event-ID
+			delete(eh.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// EventIDAfter reports whether event id a was published after b, ids are redis stream ids
+func EventIDAfter(a, b string) bool {
+	aMs, aSeq := parseEventID(a)
+	bMs, bSeq := parseEventID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+// parseEventID splits a redis stream id into its millisecond and sequence parts
+func parseEventID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
+
+// IsValidEventID reports whether the value is a redis stream id
+func IsValidEventID(id string) bool {
+	msPart, seqPart, found := strings.Cut(id, "-")
+	if !found {
+		return false
+	}
+	if _, err := strconv.ParseUint(msPart, 10, 64); err != nil {
+		return false
+	}
+	_, err := strconv.ParseUint(seqPart, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import "testing"
+
+func TestEventIDAfter(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "1700000000001-0", b: "1700000000000-0", want: true},
+		{a: "1700000000000-0", b: "1700000000001-0", want: false},
+		{a: "1700000000000-2", b: "1700000000000-1", want: true},
+		{a: "1700000000000-1", b: "1700000000000-1", want: false},
+		// Parts are compared as numbers, not as strings
+		{a: "1700000000000-10", b: "1700000000000-9", want: true},
+		{a: "10000000000000-0", b: "9999999999999-0", want: true},
+		// A newer millisecond wins over a higher sequence
+		{a: "1700000000001-0", b: "1700000000000-99", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := EventIDAfter(tt.a, tt.b); got != tt.want {
+			t.Errorf("EventIDAfter(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidEventID(t *testing.T) {
+	tests := map[string]bool{
+		"1700000000000-0": true,
+		"0-0":             true,
+		"1700000000000":   false,
+		"1700000000000-":  false,
+		"-1":              false,
+		"abc-0":           false,
+		"1700000000000-x": false,
+		"-5-0":            false,
+		"":                false,
+	}
+
+	for id, want := range tests {
+		if got := IsValidEventID(id); got != want {
+			t.Errorf("IsValidEventID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
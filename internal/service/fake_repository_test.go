@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"insider-challenge/internal/repository"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// fakeRepository serves the state the tests set up, calls to methods it does not implement panic on the nil interface
+type fakeRepository struct {
+	repository.Repository
+
+	senderState domain.SenderState
+
+	series    []domain.StatsBucket
+	histogram []domain.LatencyBucketCount
+	failures  []domain.FailureReasonCount
+	statuses  map[domain.MessageStatus]int64
+
+	suppressions map[string]*domain.Suppression
+	templates    map[string]*domain.Template // Latest version by id
+
+	blackouts []domain.BlackoutDate
+	unsent    map[domain.Priority][]domain.Message
+	messages  map[string]*domain.Message
+
+	// dedupOriginal and sentDuplicate answer the duplicate lookups, dedupLookups records their arguments
+	dedupOriginal *domain.Message
+	sentDuplicate *domain.Message
+	dedupLookups  []dedupLookup
+
+	// idempotencyKeys by client id and key, racingKey is stored by a concurrent request between the lookup and the insert
+	idempotencyKeys map[[2]string]*domain.IdempotencyKey
+	racingKey       *domain.IdempotencyKey
+}
+
+// dedupLookup holds the arguments of a duplicate lookup, messageID is empty for the ingestion lookup
+type dedupLookup struct {
+	contentHash string
+	messageID   string
+	since       time.Time
+}
+
+func (f *fakeRepository) GetSenderState(ctx context.Context) (domain.SenderState, error) {
+	return f.senderState, nil
+}
+
+func (f *fakeRepository) GetSuppression(ctx context.Context, phone string) (*domain.Suppression, error) {
+	suppression, ok := f.suppressions[phone]
+	if !ok {
+		return nil, errors.ErrSuppressionNotFound
+	}
+	return suppression, nil
+}
+
+func (f *fakeRepository) GetTemplate(ctx context.Context, id string, version int) (*domain.Template, error) {
+	tmpl, ok := f.templates[id]
+	if !ok || (version != 0 && version != tmpl.Version) {
+		return nil, errors.ErrTemplateNotFound
+	}
+	return tmpl, nil
+}
+
+func (f *fakeRepository) ListBlackoutDates(ctx context.Context, from string) ([]domain.BlackoutDate, error) {
+	return f.blackouts, nil
+}
+
+func (f *fakeRepository) GetUnsentMessages(ctx context.Context, priority domain.Priority, limit int) ([]domain.Message, error) {
+	messages := f.unsent[priority]
+	return messages[:min(limit, len(messages))], nil
+}
+
+func (f *fakeRepository) GetMessageByID(ctx context.Context, messageID string) (*domain.Message, error) {
+	return f.GetMessageByIDUnscoped(ctx, messageID)
+}
+
+func (f *fakeRepository) GetMessageByIDUnscoped(ctx context.Context, messageID string) (*domain.Message, error) {
+	msg, ok := f.messages[messageID]
+	if !ok {
+		return nil, errors.ErrMessageNotFound
+	}
+	return msg, nil
+}
+
+func (f *fakeRepository) GetIdempotencyKey(ctx context.Context, clientID, key string) (*domain.IdempotencyKey, error) {
+	return f.idempotencyKeys[[2]string{clientID, key}], nil
+}
+
+func (f *fakeRepository) CreateMessageIdempotent(ctx context.Context, message *domain.Message, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	if f.racingKey == nil {
+		return nil, errors.Wrap(errors.ErrDatabaseOperation, "fake repository stores no messages")
+	}
+	return f.racingKey, nil
+}
+
+func (f *fakeRepository) FindDedupOriginal(ctx context.Context, contentHash string, since time.Time) (*domain.Message, error) {
+	f.dedupLookups = append(f.dedupLookups, dedupLookup{contentHash: contentHash, since: since})
+	return f.dedupOriginal, nil
+}
+
+func (f *fakeRepository) FindSentDuplicate(ctx context.Context, contentHash, messageID string, since time.Time) (*domain.Message, error) {
+	f.dedupLookups = append(f.dedupLookups, dedupLookup{contentHash: contentHash, messageID: messageID, since: since})
+	return f.sentDuplicate, nil
+}
+
+func (f *fakeRepository) GetStatsSeries(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsBucket, error) {
+	return f.series, nil
+}
+
+func (f *fakeRepository) GetLatencyHistogram(ctx context.Context, filter domain.StatsFilter) ([]domain.LatencyBucketCount, error) {
+	return f.histogram, nil
+}
+
+func (f *fakeRepository) GetTopFailureReasons(ctx context.Context, filter domain.StatsFilter, limit int) ([]domain.FailureReasonCount, error) {
+	return f.failures[:min(limit, len(f.failures))], nil
+}
+
+func (f *fakeRepository) CountMessagesByStatus(ctx context.Context, filter domain.StatsFilter) (map[domain.MessageStatus]int64, error) {
+	return f.statuses, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -16,8 +17,15 @@ import (
 type WebhookResponse struct {
 	Message   string `json:"message"`
 	MessageID string `json:"messageId"`
+
+	// StatusCode and Body are kept for the message history, they are set on failed requests too
+	StatusCode int    `json:"-"`
+	Body       string `json:"-"`
 }
 
+// maxWebhookBodySize upper bound of webhook response bodies read into memory
+const maxWebhookBodySize = 64 << 10
+
 // HTTPClient handles http operations for the service
 type HTTPClient struct {
 	cfg            *config.Config
@@ -61,13 +69,18 @@ func (c *HTTPClient) SendRequest(ctx context.Context, jsonData []byte) (WebhookR
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookBodySize))
+	response := WebhookResponse{StatusCode: resp.StatusCode, Body: string(body)}
+	if err != nil {
+		return response, errors.Wrap(err, "read response")
+	}
+
 	if resp.StatusCode != http.StatusAccepted {
-		return WebhookResponse{}, errors.Wrap(errors.ErrWebhookFailed, fmt.Sprintf("unexpected status code: %d", resp.StatusCode))
+		return response, errors.Wrap(errors.ErrWebhookFailed, fmt.Sprintf("unexpected status code: %d", resp.StatusCode))
 	}
 
-	var response WebhookResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return WebhookResponse{}, errors.Wrap(err, "decode response")
+	if err := json.Unmarshal(body, &response); err != nil {
+		return response, errors.Wrap(err, "decode response")
 	}
 
 	return response, nil
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"insider-challenge/pkg/config"
+	"insider-challenge/pkg/errors"
+)
+
+func TestSendRequestKeepsTheResponseForTheHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    error
+		wantID     string
+		wantLength int
+	}{
+		{name: "accepted", status: http.StatusAccepted, body: `{"message":"Accepted","messageId":"abc-1"}`, wantID: "abc-1"},
+		{name: "rejected", status: http.StatusBadRequest, body: `{"error":"invalid recipient"}`, wantErr: errors.ErrWebhookFailed},
+		{name: "large body is cut", status: http.StatusInternalServerError, body: strings.Repeat("x", maxWebhookBodySize+100), wantErr: errors.ErrWebhookFailed, wantLength: maxWebhookBodySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(&config.Config{WebhookURL: server.URL})
+			response, err := client.SendRequest(context.Background(), []byte(`{}`))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("SendRequest() error = %v", err)
+			}
+			if tt.wantErr != nil && !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("SendRequest() error = %v, want %v", err, tt.wantErr)
+			}
+
+			wantBody := tt.body
+			if tt.wantLength > 0 {
+				wantBody = tt.body[:tt.wantLength]
+			}
+			if response.StatusCode != tt.status || response.Body != wantBody || response.MessageID != tt.wantID {
+				t.Errorf("SendRequest() = status %d, %d byte body, message id %q, want %d, %d bytes, %q",
+					response.StatusCode, len(response.Body), response.MessageID, tt.status, len(wantBody), tt.wantID)
+			}
+		})
+	}
+}
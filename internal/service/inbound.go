@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// InboundInput holds a reply reported by a provider
+type InboundInput struct {
+	From              string
+	To                string
+	Content           string
+	ProviderMessageID string
+	ReceivedAt        time.Time
+}
+
+// ReceiveInbound authenticates the provider and stores the reply, an opt-out keyword adds the sender to the suppression list.
+// Provider retries of a reply with a message id return the stored reply without handling its keyword again.
+func (s *Service) ReceiveInbound(ctx context.Context, provider, token string, input InboundInput) (*domain.InboundMessage, error) {
+	if err := s.authenticateProvider(provider, token); err != nil {
+		return nil, err
+	}
+
+	from, err := s.normalizePhone("from", input.From)
+	if err != nil {
+		return nil, err
+	}
+	input.From = from
+	if input.ReceivedAt.IsZero() {
+		input.ReceivedAt = time.Now()
+	}
+
+	var optOut *domain.Suppression
+	keyword := s.matchOptOutKeyword(input.Content)
+	if keyword != "" {
+		entries, err := s.buildSuppressions([]SuppressionInput{{
+			Phone:  input.From,
+			Reason: "replied " + keyword,
+		}}, domain.SuppressionSourceInbound)
+		if err != nil {
+			return nil, err
+		}
+		optOut = &entries[0]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	inbound := &domain.InboundMessage{
+		Provider:          provider,
+		ProviderMessageID: strings.TrimSpace(input.ProviderMessageID),
+		From:              input.From,
+		To:                strings.TrimSpace(input.To),
+		Content:           input.Content,
+		OptOutKeyword:     keyword,
+		ReceivedAt:        input.ReceivedAt,
+	}
+	existing, err := s.repo.CreateInboundMessage(ctx, inbound, optOut)
+	if err != nil {
+		return nil, errors.Wrap(err, "receive inbound message")
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	if optOut != nil {
+		s.suppressions.invalidate(ctx, []domain.Suppression{*optOut}, true)
+	}
+	return inbound, nil
+}
+
+// GetReplies retrieves a page of the replies received from a phone number, hasMore reports a following page
+func (s *Service) GetReplies(ctx context.Context, phone string, page, pageSize int) ([]domain.InboundMessage, bool, error) {
+	phone, err := s.normalizePhone("phone", phone)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	replies, err := s.repo.GetInboundMessages(ctx, phone, (page-1)*pageSize, pageSize+1)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "get replies")
+	}
+	if len(replies) > pageSize {
+		return replies[:pageSize], true, nil
+	}
+	return replies, false, nil
+}
+
+// matchOptOutKeyword returns the configured keyword the reply starts with, empty when it is not an opt-out
+func (s *Service) matchOptOutKeyword(content string) string {
+	words := strings.FieldsFunc(content, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	first := normalizeKeyword(words[0])
+	for _, keyword := range s.cfg.OptOutKeywords {
+		if normalizeKeyword(keyword) == first {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// normalizeKeyword upper cases the word so "iptal", "İptal" and "IPTAL" match the same keyword
+func normalizeKeyword(word string) string {
+	return strings.ToUpper(strings.NewReplacer("İ", "I", "ı", "i").Replace(word))
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"insider-challenge/pkg/config"
+)
+
+func TestMatchOptOutKeyword(t *testing.T) {
+	s := &Service{cfg: &config.Config{OptOutKeywords: []string{"STOP", "DUR", "IPTAL"}}}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "STOP", want: "STOP"},
+		{content: "stop", want: "STOP"},
+		{content: "  Stop please", want: "STOP"},
+		{content: "STOP!", want: "STOP"},
+		{content: "iptal", want: "IPTAL"},
+		{content: "İPTAL", want: "IPTAL"},
+		{content: "İptal edin", want: "IPTAL"},
+		{content: "dur.", want: "DUR"},
+		{content: "Please stop", want: ""},
+		{content: "STOPPED", want: ""},
+		{content: "", want: ""},
+		{content: "!!!", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := s.matchOptOutKeyword(tt.content); got != tt.want {
+			t.Errorf("matchOptOutKeyword(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
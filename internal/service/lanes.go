@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"insider-challenge/internal/repository"
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// defaultLaneWeights share of the batch slots left after the critical lane
+var defaultLaneWeights = map[domain.Priority]int{
+	domain.PriorityTransactional: 3,
+	domain.PriorityBulk:          1,
+}
+
+// weightedLanes lanes that share the batch by weight, in the order they are sent within a batch
+var weightedLanes = []domain.Priority{domain.PriorityTransactional, domain.PriorityBulk}
+
+// LaneScheduler fills sending batches, critical messages go first and the other lanes share the remaining
+// slots by smooth weighted round robin so bulk can never starve transactional and still progresses itself
+type LaneScheduler struct {
+	repo    repository.Repository
+	weights map[domain.Priority]int
+
+	// credits carry the round robin state across cycles, small batches would otherwise always favour the heaviest lane
+	credits map[domain.Priority]int
+}
+
+// NewLaneScheduler creates the lane scheduler, invalid LANE_WEIGHTS entries are logged and ignored
+func NewLaneScheduler(repo repository.Repository, cfg *config.Config) *LaneScheduler {
+	weights := make(map[domain.Priority]int, len(weightedLanes))
+	for lane, weight := range defaultLaneWeights {
+		weights[lane] = weight
+	}
+	for lane, value := range cfg.LaneWeights {
+		weight, err := strconv.Atoi(value)
+		if _, ok := weights[domain.Priority(lane)]; !ok || err != nil || weight <= 0 {
+			log.Printf("Ignoring invalid LANE_WEIGHTS entry %s:%s", lane, value)
+			continue
+		}
+		weights[domain.Priority(lane)] = weight
+	}
+
+	return &LaneScheduler{
+		repo:    repo,
+		weights: weights,
+		credits: make(map[domain.Priority]int, len(weightedLanes)),
+	}
+}
+
+// NextBatch retrieves up to size due messages, the caller serializes the cycles
+func (ls *LaneScheduler) NextBatch(ctx context.Context, size int) ([]domain.Message, error) {
+	batch, err := ls.repo.GetUnsentMessages(ctx, domain.PriorityCritical, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "get critical messages")
+	}
+
+	slots := size - len(batch)
+	if slots == 0 {
+		return batch, nil
+	}
+
+	candidates := make(map[domain.Priority][]domain.Message, len(weightedLanes))
+	for _, lane := range weightedLanes {
+		messages, err := ls.repo.GetUnsentMessages(ctx, lane, slots)
+		if err != nil {
+			return nil, errors.Wrap(err, "get "+string(lane)+" messages")
+		}
+		candidates[lane] = messages
+	}
+
+	taken := ls.share(candidates, slots)
+	for _, lane := range weightedLanes {
+		batch = append(batch, candidates[lane][:taken[lane]]...)
+	}
+	return batch, nil
+}
+
+// share hands out the slots one at a time to the lane with the most credit, lanes without messages are skipped so no slot is wasted
+func (ls *LaneScheduler) share(candidates map[domain.Priority][]domain.Message, slots int) map[domain.Priority]int {
+	taken := make(map[domain.Priority]int, len(weightedLanes))
+	for ; slots > 0; slots-- {
+		total := 0
+		var best domain.Priority
+		for _, lane := range weightedLanes {
+			if taken[lane] >= len(candidates[lane]) {
+				continue
+			}
+			ls.credits[lane] += ls.weights[lane]
+			total += ls.weights[lane]
+			if best == "" || ls.credits[lane] > ls.credits[best] {
+				best = lane
+			}
+		}
+		if best == "" {
+			break
+		}
+		ls.credits[best] -= total
+		taken[best]++
+	}
+	return taken
+}
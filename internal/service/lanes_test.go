@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+)
+
+// lanesOf returns the lane of every message of the batch in order
+func lanesOf(batch []domain.Message) []domain.Priority {
+	lanes := make([]domain.Priority, len(batch))
+	for i, message := range batch {
+		lanes[i] = message.Priority
+	}
+	return lanes
+}
+
+// pendingMessages creates count pending messages of every lane
+func pendingMessages(counts map[domain.Priority]int) map[domain.Priority][]domain.Message {
+	unsent := make(map[domain.Priority][]domain.Message, len(counts))
+	for lane, count := range counts {
+		for range count {
+			unsent[lane] = append(unsent[lane], domain.Message{Priority: lane})
+		}
+	}
+	return unsent
+}
+
+func TestLaneSchedulerNextBatch(t *testing.T) {
+	const (
+		critical      = domain.PriorityCritical
+		transactional = domain.PriorityTransactional
+		bulk          = domain.PriorityBulk
+	)
+
+	tests := []struct {
+		name    string
+		weights map[string]string
+		pending map[domain.Priority]int
+		size    int
+		want    []domain.Priority
+	}{
+		{
+			name:    "default weights",
+			pending: map[domain.Priority]int{transactional: 10, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{transactional, transactional, transactional, bulk},
+		},
+		{
+			name:    "critical goes first",
+			pending: map[domain.Priority]int{critical: 2, transactional: 10, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{critical, critical, transactional, transactional},
+		},
+		{
+			name:    "critical fills the batch",
+			pending: map[domain.Priority]int{critical: 5, transactional: 10, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{critical, critical, critical, critical},
+		},
+		{
+			name:    "empty lane leaves its slots to the others",
+			pending: map[domain.Priority]int{bulk: 10},
+			size:    4,
+			want:    []domain.Priority{bulk, bulk, bulk, bulk},
+		},
+		{
+			name:    "short lane leaves its slots to the others",
+			pending: map[domain.Priority]int{transactional: 1, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{transactional, bulk, bulk, bulk},
+		},
+		{
+			name:    "fewer messages than slots",
+			pending: map[domain.Priority]int{critical: 1, transactional: 1, bulk: 1},
+			size:    10,
+			want:    []domain.Priority{critical, transactional, bulk},
+		},
+		{
+			name:    "configured weights",
+			weights: map[string]string{"transactional": "1", "bulk": "3"},
+			pending: map[domain.Priority]int{transactional: 10, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{transactional, bulk, bulk, bulk},
+		},
+		{
+			name:    "invalid weights are ignored",
+			weights: map[string]string{"critical": "5", "bulk": "0", "transactional": "x"},
+			pending: map[domain.Priority]int{transactional: 10, bulk: 10},
+			size:    4,
+			want:    []domain.Priority{transactional, transactional, transactional, bulk},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{unsent: pendingMessages(tt.pending)}
+			scheduler := NewLaneScheduler(repo, &config.Config{LaneWeights: tt.weights})
+
+			batch, err := scheduler.NextBatch(context.Background(), tt.size)
+			if err != nil {
+				t.Fatalf("NextBatch() error = %v", err)
+			}
+			if got := lanesOf(batch); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NextBatch() lanes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaneSchedulerCarriesCreditsAcrossBatches(t *testing.T) {
+	repo := &fakeRepository{unsent: pendingMessages(map[domain.Priority]int{
+		domain.PriorityTransactional: 10,
+		domain.PriorityBulk:          10,
+	})}
+	scheduler := NewLaneScheduler(repo, &config.Config{})
+
+	// Single message batches still share the lanes 3:1 instead of always picking transactional
+	var got []domain.Priority
+	for range 8 {
+		batch, err := scheduler.NextBatch(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("NextBatch() error = %v", err)
+		}
+		got = append(got, lanesOf(batch)...)
+	}
+
+	counts := make(map[domain.Priority]int)
+	for _, lane := range got {
+		counts[lane]++
+	}
+	if counts[domain.PriorityTransactional] != 6 || counts[domain.PriorityBulk] != 2 {
+		t.Errorf("NextBatch() lanes = %v, want 6 transactional and 2 bulk", got)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"insider-challenge/pkg/config"
+	"insider-challenge/pkg/errors"
+)
+
+// leaderLeaseKey redis key of the lease held by the sender leader
+const leaderLeaseKey = "sender:leader"
+
+// leaseStore holds the leader lease shared by the replicas
+type leaseStore interface {
+	AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, key, owner string) error
+	GetLeaseOwner(ctx context.Context, key string) (string, error)
+}
+
+// redisLeases keeps the lease in the shared redis
+type redisLeases struct{}
+
+func (redisLeases) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return config.AcquireLease(ctx, key, owner, ttl)
+}
+
+func (redisLeases) RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return config.RenewLease(ctx, key, owner, ttl)
+}
+
+func (redisLeases) ReleaseLease(ctx context.Context, key, owner string) error {
+	return config.ReleaseLease(ctx, key, owner)
+}
+
+func (redisLeases) GetLeaseOwner(ctx context.Context, key string) (string, error) {
+	return config.GetLeaseOwner(ctx, key)
+}
+
+// LeaderElector keeps a redis lease so only one replica runs the message sender ticker
+type LeaderElector struct {
+	leases      leaseStore
+	instanceID  string
+	leaseTTL    time.Duration
+	stopChan    chan struct{}
+	doneChan    chan struct{}
+	isRunning   bool
+	runningLock sync.Mutex
+
+	// leaseLock guards the leadership state below
+	leaseLock   sync.RWMutex
+	isLeader    bool
+	leaseExpiry time.Time
+
+	// renewInterval how often the lease is renewed or a takeover is attempted
+	renewInterval time.Duration
+
+	// requestTimeout default timeout for redis operations
+	requestTimeout time.Duration
+}
+
+// NewLeaderElector creates a new leader elector instance
+func NewLeaderElector(cfg *config.Config) *LeaderElector {
+	return &LeaderElector{
+		leases:         redisLeases{},
+		instanceID:     cfg.InstanceID,
+		leaseTTL:       cfg.LeaderLeaseTTL,
+		renewInterval:  cfg.LeaderLeaseTTL / 3,
+		requestTimeout: 2 * time.Second,
+	}
+}
+
+// Start starts campaigning for leadership
+func (le *LeaderElector) Start() {
+	le.runningLock.Lock()
+	if le.isRunning {
+		le.runningLock.Unlock()
+		return
+	}
+	le.isRunning = true
+	le.stopChan = make(chan struct{})
+	le.doneChan = make(chan struct{})
+	le.runningLock.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(le.renewInterval)
+		defer ticker.Stop()
+		defer close(le.doneChan)
+
+		le.campaign()
+		for {
+			select {
+			case <-ticker.C:
+				le.campaign()
+			case <-le.stopChan:
+				le.release()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops campaigning and releases the lease so another replica can take over immediately
+func (le *LeaderElector) Stop() {
+	le.runningLock.Lock()
+	if !le.isRunning {
+		le.runningLock.Unlock()
+		return
+	}
+
+	close(le.stopChan)
+	le.isRunning = false
+	le.runningLock.Unlock()
+
+	<-le.doneChan
+}
+
+// IsLeader returns whether this replica currently holds a lease that has not expired
+func (le *LeaderElector) IsLeader() bool {
+	le.leaseLock.RLock()
+	defer le.leaseLock.RUnlock()
+	return le.isLeader && time.Now().Before(le.leaseExpiry)
+}
+
+// InstanceID returns the identity this replica campaigns with
+func (le *LeaderElector) InstanceID() string {
+	return le.instanceID
+}
+
+// Leader retrieves the identity of the current leader, empty if nobody holds the lease
+func (le *LeaderElector) Leader(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, le.requestTimeout)
+	defer cancel()
+
+	owner, err := le.leases.GetLeaseOwner(ctx, leaderLeaseKey)
+	if stderrors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(errors.ErrStateStore, err.Error())
+	}
+	return owner, nil
+}
+
+// campaign renews the lease when leading, otherwise tries to acquire it
+func (le *LeaderElector) campaign() {
+	ctx, cancel := context.WithTimeout(context.Background(), le.requestTimeout)
+	defer cancel()
+
+	// Deadline is taken before the request so the local view never outlives the redis lease
+	expiry := time.Now().Add(le.leaseTTL)
+
+	var held bool
+	var err error
+	if le.IsLeader() {
+		held, err = le.leases.RenewLease(ctx, leaderLeaseKey, le.instanceID, le.leaseTTL)
+	} else {
+		held, err = le.leases.AcquireLease(ctx, leaderLeaseKey, le.instanceID, le.leaseTTL)
+		if err == nil && !held {
+			// The lease may still be ours from before a restart or a failed renewal
+			held, err = le.leases.RenewLease(ctx, leaderLeaseKey, le.instanceID, le.leaseTTL)
+		}
+	}
+	if err != nil {
+		// Keep the current view, IsLeader turns false by itself once the lease expires
+		log.Printf("Leader election request failed: %v", err)
+		return
+	}
+
+	le.leaseLock.Lock()
+	defer le.leaseLock.Unlock()
+
+	if held != le.isLeader {
+		if held {
+			log.Printf("Instance %s became the sender leader", le.instanceID)
+		} else {
+			log.Printf("Instance %s lost the sender leadership", le.instanceID)
+		}
+	}
+	le.isLeader = held
+	if held {
+		le.leaseExpiry = expiry
+	}
+}
+
+// release gives up the lease if this replica holds it
+func (le *LeaderElector) release() {
+	le.leaseLock.Lock()
+	wasLeader := le.isLeader
+	le.isLeader = false
+	le.leaseLock.Unlock()
+
+	if !wasLeader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), le.requestTimeout)
+	defer cancel()
+
+	if err := le.leases.ReleaseLease(ctx, leaderLeaseKey, le.instanceID); err != nil {
+		log.Printf("Failed to release sender leadership: %v", err)
+	}
+}
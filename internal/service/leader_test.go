@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"insider-challenge/pkg/config"
+)
+
+// memoryLeases is a lease store without expiry, tests hand the lease over by setting the owner
+type memoryLeases struct {
+	mu     sync.Mutex
+	owners map[string]string
+	err    error // Returned by every call when set
+}
+
+func (m *memoryLeases) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	if _, held := m.owners[key]; held {
+		return false, nil
+	}
+	m.owners[key] = owner
+	return true, nil
+}
+
+func (m *memoryLeases) RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	return m.owners[key] == owner, nil
+}
+
+func (m *memoryLeases) ReleaseLease(ctx context.Context, key, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if m.owners[key] == owner {
+		delete(m.owners, key)
+	}
+	return nil
+}
+
+func (m *memoryLeases) GetLeaseOwner(ctx context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return "", m.err
+	}
+	owner, held := m.owners[key]
+	if !held {
+		return "", redis.Nil
+	}
+	return owner, nil
+}
+
+func newTestElector(leases *memoryLeases, instanceID string) *LeaderElector {
+	le := NewLeaderElector(&config.Config{InstanceID: instanceID, LeaderLeaseTTL: time.Minute})
+	le.leases = leases
+	return le
+}
+
+func TestLeaderElectorCampaign(t *testing.T) {
+	tests := []struct {
+		name       string
+		owner      string // Lease owner before the campaign, empty when free
+		wasLeader  bool
+		err        error
+		wantLeader bool
+		wantOwner  string
+	}{
+		{name: "acquires a free lease", wantLeader: true, wantOwner: "replica-1"},
+		{name: "lease held by another replica", owner: "replica-2", wantOwner: "replica-2"},
+		{name: "lease still held from before a restart", owner: "replica-1", wantLeader: true, wantOwner: "replica-1"},
+		{name: "leader renews", owner: "replica-1", wasLeader: true, wantLeader: true, wantOwner: "replica-1"},
+		{name: "leader lost the lease", owner: "replica-2", wasLeader: true, wantOwner: "replica-2"},
+		{name: "store unavailable keeps the leadership until the lease expires", owner: "replica-1", wasLeader: true, err: stderrors.New("connection refused"), wantLeader: true, wantOwner: "replica-1"},
+		{name: "store unavailable never grants the leadership", err: stderrors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leases := &memoryLeases{owners: make(map[string]string)}
+			if tt.owner != "" {
+				leases.owners[leaderLeaseKey] = tt.owner
+			}
+			le := newTestElector(leases, "replica-1")
+			if tt.wasLeader {
+				le.isLeader = true
+				le.leaseExpiry = time.Now().Add(time.Minute)
+			}
+			leases.err = tt.err
+
+			le.campaign()
+
+			if got := le.IsLeader(); got != tt.wantLeader {
+				t.Errorf("IsLeader() = %v, want %v", got, tt.wantLeader)
+			}
+			if got := leases.owners[leaderLeaseKey]; got != tt.wantOwner {
+				t.Errorf("lease owner = %q, want %q", got, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestLeaderElectorRenewExtendsTheLease(t *testing.T) {
+	le := newTestElector(&memoryLeases{owners: make(map[string]string)}, "replica-1")
+
+	le.campaign()
+	first := le.leaseExpiry
+	time.Sleep(time.Millisecond)
+	le.campaign()
+
+	if !le.IsLeader() || !le.leaseExpiry.After(first) {
+		t.Errorf("renewal kept the expiry at %v, want it after %v", le.leaseExpiry, first)
+	}
+}
+
+func TestLeaderElectorExpiredLease(t *testing.T) {
+	le := newTestElector(&memoryLeases{owners: make(map[string]string)}, "replica-1")
+	le.isLeader = true
+	le.leaseExpiry = time.Now().Add(-time.Millisecond)
+
+	// A replica that could not renew in time must stop acting as leader even before the next campaign
+	if le.IsLeader() {
+		t.Error("IsLeader() = true with an expired lease")
+	}
+}
+
+func TestLeaderElectorStopReleasesTheLease(t *testing.T) {
+	leases := &memoryLeases{owners: make(map[string]string)}
+	leader := newTestElector(leases, "replica-1")
+	follower := newTestElector(leases, "replica-2")
+
+	leader.campaign()
+	leader.Start()
+	follower.campaign()
+	if owner, _ := leader.Leader(context.Background()); owner != "replica-1" || follower.IsLeader() {
+		t.Fatalf("Leader() = %q, follower leading %v, want replica-1 alone", owner, follower.IsLeader())
+	}
+
+	leader.Stop()
+	if owner, err := leader.Leader(context.Background()); owner != "" || err != nil {
+		t.Fatalf("Leader() after Stop = %q, %v, want no leader", owner, err)
+	}
+
+	follower.campaign()
+	if !follower.IsLeader() || leader.IsLeader() {
+		t.Errorf("after takeover leader leading %v, follower leading %v", leader.IsLeader(), follower.IsLeader())
+	}
+}
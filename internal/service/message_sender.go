@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -11,17 +13,29 @@ import (
 	"insider-challenge/pkg/config"
 	domain "insider-challenge/pkg/domain"
 	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/phone"
 )
 
 // MessageSender handles the message sending
 type MessageSender struct {
-	repo        repository.Repository
-	cfg         *config.Config
-	httpClient  *HTTPClient
-	stopChan    chan struct{}
-	doneChan    chan struct{}
-	isRunning   bool
-	runningLock sync.Mutex
+	repo         repository.Repository
+	cfg          *config.Config
+	httpClient   *HTTPClient
+	leader       *LeaderElector
+	events       *EventHub
+	callbacks    *CallbackDispatcher
+	suppressions *SuppressionList
+	policy       *CountryPolicy
+	schedule     *SendingSchedule
+	lanes        *LaneScheduler
+	wakeChan     chan struct{}
+	stopChan     chan struct{}
+	doneChan     chan struct{}
+	isRunning    bool
+	runningLock  sync.Mutex
+
+	// cycleLock serializes ticker, wake-up and manual sends so a message is never sent twice
+	cycleLock sync.Mutex
 
 	// DefaultMessageBatchSize default number of messages batch
 	messageBatchSize int
@@ -29,6 +43,9 @@ type MessageSender struct {
 	// DefaultTickerInterval default interval sender ticker
 	tickerInterval time.Duration
 
+	// wakeDebounce coalesces wake-ups received within this window into a single cycle
+	wakeDebounce time.Duration
+
 	// DefaultHTTPTimeout default timeout http operations
 	httpTimeout time.Duration
 
@@ -37,15 +54,23 @@ type MessageSender struct {
 }
 
 // NewMessageSender creates a new message sender instance
-func NewMessageSender(repo repository.Repository, cfg *config.Config) *MessageSender {
+func NewMessageSender(repo repository.Repository, cfg *config.Config, events *EventHub, callbacks *CallbackDispatcher, suppressions *SuppressionList, policy *CountryPolicy, schedule *SendingSchedule) *MessageSender {
 	return &MessageSender{
 		repo:             repo,
 		cfg:              cfg,
 		httpClient:       NewHTTPClient(cfg),
+		events:           events,
+		callbacks:        callbacks,
+		suppressions:     suppressions,
+		policy:           policy,
+		schedule:         schedule,
+		lanes:            NewLaneScheduler(repo, cfg),
+		wakeChan:         make(chan struct{}, 1),
 		stopChan:         make(chan struct{}),
 		doneChan:         make(chan struct{}),
 		messageBatchSize: 2,
 		tickerInterval:   2 * time.Minute,
+		wakeDebounce:     cfg.NotifyDebounce,
 		httpTimeout:      10 * time.Second,
 		requestTimeout:   5 * time.Second,
 	}
@@ -59,32 +84,40 @@ func (ms *MessageSender) Start() {
 		return
 	}
 	ms.isRunning = true
-	ms.stopChan = make(chan struct{})
-	ms.doneChan = make(chan struct{})
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	ms.stopChan = stop
+	ms.doneChan = done
 	ms.runningLock.Unlock()
 
+	// The goroutine only uses its own channels, a later Start replaces the fields while it may still be finishing a cycle
 	go func() {
 		ticker := time.NewTicker(ms.tickerInterval)
 		defer ticker.Stop()
-		defer close(ms.doneChan)
+		defer close(done)
+
+		// debounce is armed by the first wake-up and fires once for the whole burst
+		var debounce <-chan time.Time
 
 		for {
 			select {
 			case <-ticker.C:
-				if err := ms.sendMessages(); err != nil {
-					log.Printf("Failed to send messages: %v", err)
+				ms.runCycle()
+			case <-ms.wakeChan:
+				if debounce == nil {
+					debounce = time.After(ms.wakeDebounce)
 				}
-			case <-ms.stopChan:
-				ms.runningLock.Lock()
-				ms.isRunning = false
-				ms.runningLock.Unlock()
+			case <-debounce:
+				debounce = nil
+				ms.runCycle()
+			case <-stop:
 				return
 			}
 		}
 	}()
 }
 
-// Stop stops the message sender service gracefully
+// Stop stops the message sender service gracefully, it returns once the running cycle finished
 func (ms *MessageSender) Stop() {
 	ms.runningLock.Lock()
 	if !ms.isRunning {
@@ -92,14 +125,13 @@ func (ms *MessageSender) Stop() {
 		return
 	}
 
-	if ms.stopChan != nil {
-		close(ms.stopChan)
-		ms.stopChan = nil
-	}
+	close(ms.stopChan)
+	done := ms.doneChan
+	ms.stopChan = nil
 	ms.isRunning = false
 	ms.runningLock.Unlock()
 
-	<-ms.doneChan
+	<-done
 }
 
 // IsRunning returns whether the message sender is currently running
@@ -109,32 +141,398 @@ func (ms *MessageSender) IsRunning() bool {
 	return ms.isRunning
 }
 
-// sendMessages retrieves and sends unsent messages in batches
-func (ms *MessageSender) sendMessages() error {
+// Wake requests a sending cycle without waiting for the ticker, the ticker stays as a safety net
+func (ms *MessageSender) Wake() {
+	select {
+	case ms.wakeChan <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// CycleResult summarizes a single sending cycle
+type CycleResult struct {
+	Sent       int
+	Failed     int
+	Suppressed int
+	Blocked    int
+	Deferred   int
+	Duplicates int
+}
+
+// runCycle sends one batch when this replica is allowed to
+func (ms *MessageSender) runCycle() {
+	if !ms.isLeader() {
+		return
+	}
+	if _, err := ms.sendMessages(); err != nil {
+		log.Printf("Failed to send messages: %v", err)
+	}
+}
+
+// Trigger runs one sending cycle immediately, serialized with the ticker
+func (ms *MessageSender) Trigger() (CycleResult, error) {
+	if !ms.isLeader() {
+		return CycleResult{}, errors.ErrNotLeader
+	}
+	return ms.sendMessages()
+}
+
+// SendOne sends a single pending message synchronously, serialized with the ticker
+func (ms *MessageSender) SendOne(ctx context.Context, messageID string) (*domain.Message, WebhookResponse, error) {
+	if !ms.isLeader() {
+		return nil, WebhookResponse{}, errors.ErrNotLeader
+	}
+
+	ms.cycleLock.Lock()
+	defer ms.cycleLock.Unlock()
+
+	// The lease may have expired while waiting for the running cycle
+	if !ms.isLeader() {
+		return nil, WebhookResponse{}, errors.ErrNotLeader
+	}
+
+	msg, err := ms.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, WebhookResponse{}, err
+	}
+	if msg.Status != domain.MessageStatusPending {
+		return nil, WebhookResponse{}, errors.Wrap(errors.ErrMessageNotPending, "message ID: "+messageID)
+	}
+
+	action := &domain.MessageEvent{
+		MessageID: msg.ID,
+		Kind:      domain.MessageEventAction,
+		Action:    domain.MessageActionSend,
+	}
+	if err := ms.repo.RecordMessageEvent(ctx, action); err != nil {
+		log.Printf("Failed to record send action of message %s: %v", msg.ID, err)
+	}
+
+	response, err := ms.deliver(ctx, *msg)
+	if err != nil {
+		return nil, WebhookResponse{}, err
+	}
+
+	sent, err := ms.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, WebhookResponse{}, err
+	}
+	return sent, response, nil
+}
+
+// SetLeaderElector restricts the ticker to the replica holding the leader lease
+func (ms *MessageSender) SetLeaderElector(leader *LeaderElector) {
+	ms.leader = leader
+}
+
+// isLeader reports whether this replica should run the ticker, always true without leader election
+func (ms *MessageSender) isLeader() bool {
+	return ms.leader == nil || ms.leader.IsLeader()
+}
+
+// sendMessages retrieves and sends unsent messages in batches filled lane by lane
+func (ms *MessageSender) sendMessages() (CycleResult, error) {
+	ms.cycleLock.Lock()
+	defer ms.cycleLock.Unlock()
+
 	ctx, cancel := context.WithTimeout(context.Background(), ms.httpTimeout)
 	defer cancel()
 
-	messages, err := ms.repo.GetUnsentMessages(ctx, ms.messageBatchSize)
+	var result CycleResult
+
+	messages, err := ms.lanes.NextBatch(ctx, ms.messageBatchSize)
 	if err != nil {
-		return errors.Wrap(err, "get unsent messages")
+		return result, errors.Wrap(err, "get unsent messages")
 	}
 
 	for _, msg := range messages {
-		if err := ms.sendMessage(ctx, msg); err != nil {
+		// The lease can expire while the batch is sent, the new leader must never send alongside this replica
+		if !ms.isLeader() {
+			return result, errors.Wrap(errors.ErrNotLeader, "leadership lost during the batch")
+		}
+
+		if _, err := ms.deliver(ctx, msg); err != nil {
+			switch {
+			case stderrors.Is(err, errors.ErrRecipientSuppressed):
+				result.Suppressed++
+				continue
+			case stderrors.Is(err, errors.ErrDestinationBlocked):
+				result.Blocked++
+				continue
+			case stderrors.Is(err, errors.ErrMessageDeferred):
+				result.Deferred++
+				continue
+			case stderrors.Is(err, errors.ErrDuplicateMessage):
+				result.Duplicates++
+				continue
+			}
 			log.Printf("Failed to send message %s: %v", msg.ID, err)
+			result.Failed++
 			continue
 		}
+		result.Sent++
+	}
+
+	return result, nil
+}
+
+// deliver sends a message and records the outcome of the attempt, suppressed recipients and duplicates are never
+// sent to and the sending policy blocks or defers the message before it is sent
+func (ms *MessageSender) deliver(ctx context.Context, msg domain.Message) (WebhookResponse, error) {
+	ms.announce(ctx, msg)
+
+	// Rows inserted outside the service skip its validation, the suppression list only holds normalized numbers
+	to, err := phone.Normalize(msg.To, ms.cfg.DefaultRegion)
+	if err != nil {
+		ms.fail(ctx, msg, "invalid recipient: "+err.Error())
+		return WebhookResponse{}, errors.Wrap(errors.ErrInvalidRequest, "invalid recipient of message "+msg.ID.String())
+	}
+	msg.To = to
+
+	suppression, err := ms.suppressions.Check(ctx, msg.To)
+	if err != nil {
+		// The message stays pending without counting an attempt and is checked again next cycle
+		return WebhookResponse{}, errors.Wrap(err, "check suppression list")
+	}
+	if suppression != nil {
+		ms.suppress(ctx, msg, *suppression)
+		return WebhookResponse{}, errors.Wrap(errors.ErrRecipientSuppressed, "message ID: "+msg.ID.String())
+	}
+
+	original, err := ms.sentDuplicate(ctx, msg)
+	if err != nil {
+		return WebhookResponse{}, err
+	}
+	if original != nil {
+		ms.markDuplicate(ctx, msg, *original)
+		return WebhookResponse{}, errors.Wrap(errors.ErrDuplicateMessage, "duplicate of "+original.ID.String())
+	}
+
+	decision, err := ms.evaluatePolicy(ctx, msg, time.Now())
+	if err != nil {
+		// Like a failed suppression check the message stays pending without counting an attempt
+		return WebhookResponse{}, errors.Wrap(err, "evaluate sending policy")
+	}
+	if decision.Blocked {
+		ms.block(ctx, msg, decision.Reason)
+		return WebhookResponse{}, errors.Wrap(errors.ErrDestinationBlocked, "message ID: "+msg.ID.String())
+	}
+	if !decision.DeferUntil.IsZero() {
+		ms.deferMessage(ctx, msg, decision)
+		return WebhookResponse{}, errors.Wrap(errors.ErrMessageDeferred, fmt.Sprintf("%s until %s", decision.Reason, decision.DeferUntil.Format(time.RFC3339)))
+	}
+
+	start := time.Now()
+	response, sendErr := ms.sendMessage(ctx, msg)
+	stat := domain.DeliveryStat{
+		At:       start,
+		Provider: ms.cfg.ProviderName,
+		Outcome:  domain.DeliveryOutcomeSent,
+		Latency:  time.Since(start),
+	}
+
+	attempt := domain.MessageEvent{
+		Kind:         domain.MessageEventAttempt,
+		Provider:     ms.cfg.ProviderName,
+		StatusCode:   response.StatusCode,
+		LatencyMs:    stat.Latency.Milliseconds(),
+		ResponseBody: response.Body,
+	}
+
+	event := domain.LifecycleEvent{
+		Type:       domain.LifecycleEventSent,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusSent,
+		Provider:   ms.cfg.ProviderName,
+		OccurredAt: time.Now(),
+	}
+
+	if sendErr != nil {
+		stat.Outcome = domain.DeliveryOutcomeFailed
+		stat.Reason = failureReason(sendErr)
+		event.Type = domain.LifecycleEventFailed
+		attempt.Error = sendErr.Error()
+		event.Status = ms.recordFailure(ctx, msg, sendErr, attempt)
+		event.Error = stat.Reason
+		if event.Status == domain.MessageStatusFailed {
+			stat.Outcome = domain.DeliveryOutcomeDead
+			event.Type = domain.LifecycleEventDead
+		}
+		ms.recordStat(ctx, stat)
+		ms.events.Publish(event)
+		ms.callbacks.Enqueue(ctx, msg, event, "")
+		return WebhookResponse{}, sendErr
+	}
+
+	if err := ms.repo.MarkMessageAsSent(ctx, msg.ID.String(), ms.cfg.ProviderName, response.MessageID, attempt); err != nil {
+		// The webhook accepted the message, report it sent even though the row could not be updated
+		log.Printf("Failed to mark message %s as sent: %v", msg.ID, err)
+	}
+	ms.recordStat(ctx, stat)
+	ms.events.Publish(event)
+	ms.callbacks.Enqueue(ctx, msg, event, response.MessageID)
+
+	return response, nil
+}
+
+// announce publishes the created event of a message inserted outside the service, such as the sample data or a row
+// inserted with sql, so stream consumers see it created before it is sent. Messages created through the api are skipped.
+func (ms *MessageSender) announce(ctx context.Context, msg domain.Message) {
+	if msg.AnnouncedAt != nil {
+		return
+	}
+
+	announced, err := ms.repo.MarkMessageAnnounced(ctx, msg.ID.String())
+	if err != nil {
+		log.Printf("Failed to announce message %s: %v", msg.ID, err)
+		return
+	}
+	if announced {
+		ms.events.Publish(createdEvent(msg))
+	}
+}
+
+// fail moves a message that can never be sent to the failed state without an attempt
+func (ms *MessageSender) fail(ctx context.Context, msg domain.Message, reason string) {
+	if err := ms.repo.MarkMessageFailed(ctx, msg.ID.String(), reason); err != nil {
+		log.Printf("Failed to mark message %s as failed: %v", msg.ID, err)
+		return
+	}
+
+	event := domain.LifecycleEvent{
+		Type:       domain.LifecycleEventDead,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusFailed,
+		Error:      reason,
+		OccurredAt: time.Now(),
+	}
+	ms.events.Publish(event)
+	ms.callbacks.Enqueue(ctx, msg, event, "")
+}
+
+// suppress moves the message to the suppressed terminal state
+func (ms *MessageSender) suppress(ctx context.Context, msg domain.Message, suppression domain.Suppression) {
+	reason := "recipient suppressed"
+	if suppression.Reason != "" {
+		reason += ": " + suppression.Reason
+	}
+
+	if err := ms.repo.MarkMessageSuppressed(ctx, msg.ID.String(), reason); err != nil {
+		log.Printf("Failed to mark message %s as suppressed: %v", msg.ID, err)
+		return
+	}
+
+	event := domain.LifecycleEvent{
+		Type:       domain.LifecycleEventSuppressed,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusSuppressed,
+		Error:      reason,
+		OccurredAt: time.Now(),
+	}
+	ms.events.Publish(event)
+	ms.callbacks.Enqueue(ctx, msg, event, "")
+}
+
+// evaluatePolicy blocks messages to countries that are not allowed, then defers them outside the sending window, on blackout
+// dates, in the country quiet hours and over the country rate limit. Bypassing messages skip everything but the block and the rate.
+func (ms *MessageSender) evaluatePolicy(ctx context.Context, msg domain.Message, now time.Time) (PolicyDecision, error) {
+	country := msg.Country
+	if country == "" {
+		country = phone.RegionOf(msg.To)
+	}
+
+	if reason := ms.policy.Block(country); reason != "" {
+		return PolicyDecision{Blocked: true, Reason: reason}, nil
+	}
 
-		if err := ms.repo.MarkMessageAsSent(ctx, msg.ID.String()); err != nil {
-			log.Printf("Failed to mark message %s as sent: %v", msg.ID, err)
+	if !msg.BypassQuietHours {
+		until, reason, err := ms.schedule.NextOpen(ctx, now)
+		if err != nil {
+			return PolicyDecision{}, err
+		}
+		if !until.IsZero() {
+			return PolicyDecision{DeferUntil: until, Reason: reason}, nil
 		}
 	}
 
-	return nil
+	return ms.policy.Throttle(ctx, country, now, msg.BypassQuietHours)
+}
+
+// block moves the message to the blocked terminal state
+func (ms *MessageSender) block(ctx context.Context, msg domain.Message, reason string) {
+	if err := ms.repo.MarkMessageBlocked(ctx, msg.ID.String(), reason); err != nil {
+		log.Printf("Failed to mark message %s as blocked: %v", msg.ID, err)
+		return
+	}
+
+	event := domain.LifecycleEvent{
+		Type:       domain.LifecycleEventBlocked,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusBlocked,
+		Error:      reason,
+		OccurredAt: time.Now(),
+	}
+	ms.events.Publish(event)
+	ms.callbacks.Enqueue(ctx, msg, event, "")
+}
+
+// deferMessage keeps the message pending and hides it from the sender until the decision allows it
+func (ms *MessageSender) deferMessage(ctx context.Context, msg domain.Message, decision PolicyDecision) {
+	if err := ms.repo.DeferMessage(ctx, msg.ID.String(), decision.DeferUntil, decision.Reason); err != nil {
+		log.Printf("Failed to defer message %s: %v", msg.ID, err)
+		return
+	}
+
+	ms.events.Publish(domain.LifecycleEvent{
+		Type:       domain.LifecycleEventDeferred,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     domain.MessageStatusPending,
+		Error:      decision.Reason,
+		OccurredAt: time.Now(),
+	})
+}
+
+// recordFailure counts the failed attempt, the message is marked failed after the maximum attempts
+func (ms *MessageSender) recordFailure(ctx context.Context, msg domain.Message, sendErr error, attempt domain.MessageEvent) domain.MessageStatus {
+	status, err := ms.repo.RecordSendFailure(ctx, msg.ID.String(), sendErr.Error(), ms.cfg.MaxSendAttempts, attempt)
+	if err != nil {
+		log.Printf("Failed to record send failure of message %s: %v", msg.ID, err)
+		return msg.Status
+	}
+	if status == domain.MessageStatusFailed {
+		log.Printf("Message %s failed permanently after %d attempts", msg.ID, ms.cfg.MaxSendAttempts)
+	}
+	return status
+}
+
+// recordStat feeds the attempt into the statistic rollups, failures never block sending
+func (ms *MessageSender) recordStat(ctx context.Context, stat domain.DeliveryStat) {
+	if err := ms.repo.RecordDeliveryStat(ctx, stat); err != nil {
+		log.Printf("Failed to record delivery stat: %v", err)
+	}
+}
+
+// failureReason normalizes send errors into low cardinality reasons for the statistics
+func failureReason(err error) string {
+	var appErr *errors.AppError
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case stderrors.Is(err, errors.ErrWebhookFailed) && stderrors.As(err, &appErr):
+		return appErr.Op
+	default:
+		return "request error"
+	}
 }
 
 // sendMessage sends a single message to the configured webhook uri
-func (ms *MessageSender) sendMessage(ctx context.Context, msg domain.Message) error {
+func (ms *MessageSender) sendMessage(ctx context.Context, msg domain.Message) (WebhookResponse, error) {
 	reqCtx, cancel := context.WithTimeout(ctx, ms.requestTimeout)
 	defer cancel()
 
@@ -145,17 +543,17 @@ func (ms *MessageSender) sendMessage(ctx context.Context, msg domain.Message) er
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return errors.Wrap(err, "marshal payload")
+		return WebhookResponse{}, errors.Wrap(err, "marshal payload")
 	}
 
 	response, err := ms.httpClient.SendRequest(reqCtx, jsonData)
 	if err != nil {
-		return err
+		return response, err
 	}
 
 	if err := config.CacheMessageID(ctx, msg.ID.String(), response.MessageID); err != nil {
 		log.Printf("Failed to cache message ID %s: %v", msg.ID, err)
 	}
 
-	return nil
+	return response, nil
 }
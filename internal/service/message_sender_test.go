@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+func TestMessageSenderStopsWithoutLease(t *testing.T) {
+	msg := domain.Message{ID: uuid.New(), Priority: domain.PriorityTransactional, Status: domain.MessageStatusPending}
+	repo := &fakeRepository{
+		unsent:   map[domain.Priority][]domain.Message{domain.PriorityTransactional: {msg}},
+		messages: map[string]*domain.Message{msg.ID.String(): &msg},
+	}
+	ms := NewMessageSender(repo, &config.Config{}, nil, nil, nil, nil, nil)
+
+	// The lease expired after the cycle started, nothing of the batch may be delivered
+	leader := NewLeaderElector(&config.Config{InstanceID: "replica-1", LeaderLeaseTTL: time.Minute})
+	leader.isLeader = true
+	leader.leaseExpiry = time.Now().Add(-time.Second)
+	ms.SetLeaderElector(leader)
+
+	result, err := ms.sendMessages()
+	if !stderrors.Is(err, errors.ErrNotLeader) {
+		t.Fatalf("sendMessages() error = %v, want %v", err, errors.ErrNotLeader)
+	}
+	if result != (CycleResult{}) {
+		t.Errorf("sendMessages() = %+v, want nothing sent", result)
+	}
+
+	if _, _, err := ms.SendOne(context.Background(), msg.ID.String()); !stderrors.Is(err, errors.ErrNotLeader) {
+		t.Errorf("SendOne() error = %v, want %v", err, errors.ErrNotLeader)
+	}
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/phone"
+)
+
+// Limits matching the sizes of the idempotency_keys columns
+const (
+	maxIdempotencyKeyLength = 255
+	maxClientIDLength       = 64
+)
+
+// NewMessage holds the fields of a message to be created, the content is either given or rendered from a template
+type NewMessage struct {
+	To          string
+	Content     string
+	CallbackURL string
+
+	TemplateID      string
+	TemplateVersion int
+	Locale          string // Template variant and transliteration language, DEFAULT_LOCALE when empty
+	Variables       map[string]string
+
+	// Transliterate overrides TRANSLITERATE for this message
+	Transliterate *bool
+
+	// BypassQuietHours sends the message outside the sending window, on blackout dates and in country quiet hours
+	BypassQuietHours bool
+
+	// Priority is the sending lane, DEFAULT_PRIORITY when empty
+	Priority domain.Priority
+}
+
+// CreateMessage validates and stores a new pending message
+func (s *Service) CreateMessage(ctx context.Context, input NewMessage) (*domain.Message, error) {
+	msg, err := s.buildMessage(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	claimed, err := s.checkDuplicate(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.storeMessage(ctx, msg, nil); err != nil {
+		if claimed {
+			s.releaseDuplicateClaim(ctx, msg)
+		}
+		return nil, errors.Wrap(err, "create message")
+	}
+	return msg, nil
+}
+
+// Idempotency identifies a request that may be retried, keys are unique per client. The client id only keeps the callers'
+// keys apart, it is not authenticated and is no security boundary.
+type Idempotency struct {
+	ClientID string
+	Key      string
+}
+
+// CreateMessageIdempotent creates the message once per key within IDEMPOTENCY_TTL. A retry returns the message created by
+// the first request and reports it as replayed, a key reused with a different request is rejected. The key is looked up
+// before the request is validated, so a reused key answers the same whatever the new request contains.
+func (s *Service) CreateMessageIdempotent(ctx context.Context, input NewMessage, idempotency Idempotency) (*domain.Message, bool, error) {
+	if len(idempotency.Key) > maxIdempotencyKeyLength {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("Idempotency-Key exceeds %d characters", maxIdempotencyKeyLength))
+	}
+	// Without a client id every caller would share one key space and could replay each other's messages by guessing keys
+	if idempotency.ClientID == "" {
+		return nil, false, &errors.ValidationError{Field: "X-Client-ID", Code: "required", Message: "is required with Idempotency-Key"}
+	}
+	if len(idempotency.ClientID) > maxClientIDLength {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("X-Client-ID exceeds %d characters", maxClientIDLength))
+	}
+
+	hash, err := requestHash(input)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "hash request")
+	}
+
+	existing, err := s.getIdempotencyKey(ctx, idempotency)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return s.replay(ctx, existing, hash)
+	}
+
+	msg, err := s.buildMessage(ctx, input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	now := time.Now()
+	key := &domain.IdempotencyKey{
+		ClientID:    idempotency.ClientID,
+		Key:         idempotency.Key,
+		RequestHash: hash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.cfg.IdempotencyTTL),
+	}
+	claimed, err := s.checkDuplicate(ctx, msg)
+	if err != nil {
+		return nil, false, err
+	}
+	// A concurrent request with the same key may have stored it since the lookup
+	existing, err = s.storeMessage(ctx, msg, key)
+	if (err != nil || existing != nil) && claimed {
+		s.releaseDuplicateClaim(ctx, msg)
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "create message")
+	}
+	if existing == nil {
+		return msg, false, nil
+	}
+	return s.replay(ctx, existing, hash)
+}
+
+// getIdempotencyKey retrieves the live key of an earlier request, nil when the key is new
+func (s *Service) getIdempotencyKey(ctx context.Context, idempotency Idempotency) (*domain.IdempotencyKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	existing, err := s.repo.GetIdempotencyKey(ctx, idempotency.ClientID, idempotency.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "get idempotency key")
+	}
+	return existing, nil
+}
+
+// replay returns the message created by the earlier request with the key, a key reused with a different request is rejected
+func (s *Service) replay(ctx context.Context, existing *domain.IdempotencyKey, hash string) (*domain.Message, bool, error) {
+	if existing.RequestHash != hash {
+		return nil, false, errors.Wrap(errors.ErrIdempotencyKeyReused, "Idempotency-Key: "+existing.Key)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	original, err := s.repo.GetMessageByIDUnscoped(ctx, existing.MessageID.String())
+	if err != nil {
+		return nil, false, errors.Wrap(err, "get replayed message")
+	}
+	return original, true, nil
+}
+
+// requestHash fingerprints the request fields, json sorts the variables so equal requests hash equally
+func requestHash(input NewMessage) (string, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// buildMessage renders the template when given and validates the message fields
+func (s *Service) buildMessage(ctx context.Context, input NewMessage) (*domain.Message, error) {
+	var rendered *RenderedTemplate
+	if input.TemplateID != "" {
+		if input.Content != "" {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, "content and template_id are mutually exclusive")
+		}
+
+		var err error
+		rendered, err = s.RenderTemplate(ctx, RenderInput{
+			TemplateID: input.TemplateID,
+			Version:    input.TemplateVersion,
+			Locale:     input.Locale,
+			Variables:  input.Variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		input.Content = rendered.Content
+	}
+
+	language := input.Locale
+	if rendered != nil {
+		language = rendered.Locale
+	}
+	if language == "" {
+		language = s.cfg.DefaultLocale
+	}
+
+	transliterate := s.cfg.Transliterate
+	if input.Transliterate != nil {
+		transliterate = *input.Transliterate
+	}
+
+	original := input.Content
+	content, analysis, err := s.prepareContent(input.Content, language, transliterate)
+	if err != nil {
+		return nil, err
+	}
+	input.Content = content
+
+	if err := validateNewMessage(input); err != nil {
+		return nil, err
+	}
+	to, err := s.normalizePhone("to", input.To)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := input.Priority
+	if priority == "" {
+		priority = s.defaultPriority()
+	}
+	if !priority.IsValid() {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "priority must be critical, transactional or bulk")
+	}
+
+	msg := &domain.Message{
+		To:               to,
+		ContentHash:      contentHash(to, input.Content),
+		Country:          phone.RegionOf(to),
+		BypassQuietHours: input.BypassQuietHours,
+		Content:          input.Content,
+		Encoding:         string(analysis.Encoding),
+		Segments:         analysis.Segments,
+		Locale:           input.Locale,
+		Status:           domain.MessageStatusPending,
+		Priority:         priority,
+		CallbackURL:      input.CallbackURL,
+	}
+	if content != original {
+		msg.OriginalContent = original
+		msg.Transliterated = true
+	}
+	if rendered != nil {
+		msg.TemplateID = rendered.TemplateID
+		msg.TemplateVersion = rendered.Version
+		msg.Locale = rendered.Locale
+	}
+	return msg, nil
+}
+
+// GetMessage retrieves the full state of a message, including cancelled ones
+func (s *Service) GetMessage(ctx context.Context, messageID string) (*domain.Message, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	msg, err := s.repo.GetMessageByIDUnscoped(ctx, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get message")
+	}
+	return msg, nil
+}
+
+// CancelMessage cancels a pending message so it is never sent
+func (s *Service) CancelMessage(ctx context.Context, messageID string) (*domain.Message, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	msg, err := s.repo.CancelMessage(ctx, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "cancel message")
+	}
+	return msg, nil
+}
+
+// ResendMessage clones a sent message into a new pending message
+func (s *Service) ResendMessage(ctx context.Context, messageID string) (*domain.Message, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	original, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "resend message")
+	}
+	if original.Status != domain.MessageStatusSent {
+		return nil, errors.Wrap(errors.ErrMessageNotSent, "message ID: "+messageID)
+	}
+
+	// Messages stored before numbers were normalized may still hold a local number
+	to, err := s.normalizePhone("to", original.To)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &domain.Message{
+		To:               to,
+		Country:          phone.RegionOf(to),
+		BypassQuietHours: original.BypassQuietHours,
+		Priority:         original.Priority,
+		Content:          original.Content,
+		Encoding:         original.Encoding,
+		Segments:         original.Segments,
+		OriginalContent:  original.OriginalContent,
+		Transliterated:   original.Transliterated,
+		Status:           domain.MessageStatusPending,
+		CallbackURL:      original.CallbackURL,
+		TemplateID:       original.TemplateID,
+		TemplateVersion:  original.TemplateVersion,
+		Locale:           original.Locale,
+	}
+	if _, err := s.storeMessage(ctx, clone, nil); err != nil {
+		return nil, errors.Wrap(err, "resend message")
+	}
+
+	action := &domain.MessageEvent{
+		MessageID: original.ID,
+		Kind:      domain.MessageEventAction,
+		Action:    domain.MessageActionResend,
+		Detail:    "resent as " + clone.ID.String(),
+	}
+	if err := s.repo.RecordMessageEvent(ctx, action); err != nil {
+		log.Printf("Failed to record resend action of message %s: %v", original.ID, err)
+	}
+
+	return clone, nil
+}
+
+// GetMessageEvents retrieves the history of a message, including cancelled ones
+func (s *Service) GetMessageEvents(ctx context.Context, messageID string) ([]domain.MessageEvent, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if _, err := s.repo.GetMessageByIDUnscoped(ctx, messageID); err != nil {
+		return nil, errors.Wrap(err, "get message events")
+	}
+
+	events, err := s.repo.GetMessageEvents(ctx, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get message events")
+	}
+	return events, nil
+}
+
+// storeMessage is the single insert path of the service and publishes the created event of the stored message. With a key
+// the message is only stored when the key is new, the existing key is returned otherwise and nothing is published.
+func (s *Service) storeMessage(ctx context.Context, msg *domain.Message, key *domain.IdempotencyKey) (*domain.IdempotencyKey, error) {
+	now := time.Now()
+	msg.AnnouncedAt = &now
+
+	if key == nil {
+		if err := s.repo.CreateMessage(ctx, msg); err != nil {
+			return nil, err
+		}
+	} else {
+		existing, err := s.repo.CreateMessageIdempotent(ctx, msg, key)
+		if err != nil || existing != nil {
+			return existing, err
+		}
+	}
+
+	s.publishCreated(ctx, msg)
+	return nil, nil
+}
+
+// publishCreated broadcasts the created event of a new message, a duplicate also reaches its final state
+func (s *Service) publishCreated(ctx context.Context, msg *domain.Message) {
+	s.events.Publish(createdEvent(*msg))
+
+	if msg.Status == domain.MessageStatusDuplicate {
+		event := domain.LifecycleEvent{
+			Type:       domain.LifecycleEventDuplicate,
+			MessageID:  msg.ID,
+			To:         msg.To,
+			Status:     msg.Status,
+			Error:      msg.LastError,
+			OccurredAt: msg.CreatedAt,
+		}
+		s.events.Publish(event)
+		s.callbacks.Enqueue(ctx, *msg, event, "")
+	}
+}
+
+// createdEvent builds the created event of the message
+func createdEvent(msg domain.Message) domain.LifecycleEvent {
+	return domain.LifecycleEvent{
+		Type:       domain.LifecycleEventCreated,
+		MessageID:  msg.ID,
+		To:         msg.To,
+		Status:     msg.Status,
+		OccurredAt: msg.CreatedAt,
+	}
+}
+
+// defaultPriority returns the lane of messages created without a priority
+func (s *Service) defaultPriority() domain.Priority {
+	if priority := domain.Priority(s.cfg.DefaultPriority); priority.IsValid() {
+		return priority
+	}
+	return domain.PriorityTransactional
+}
+
+// validateNewMessage checks the fields of a new message
+func validateNewMessage(input NewMessage) error {
+	if strings.TrimSpace(input.To) == "" {
+		return errors.Wrap(errors.ErrInvalidRequest, "to is required")
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return errors.Wrap(errors.ErrInvalidRequest, "content is required")
+	}
+	if utf8.RuneCountInString(input.Content) > domain.MaxContentLength {
+		return errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("content exceeds %d characters", domain.MaxContentLength))
+	}
+	if input.CallbackURL != "" {
+		if err := validateCallbackURL(input.CallbackURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMessageID rejects ids that are not uuids before they reach the database
+func validateMessageID(messageID string) error {
+	if _, err := uuid.Parse(messageID); err != nil {
+		return errors.Wrap(errors.ErrInvalidRequest, "invalid message ID: "+messageID)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+func TestRequestHash(t *testing.T) {
+	yes, no := true, false
+	base := NewMessage{
+		To:         "+905551234567",
+		TemplateID: "otp",
+		Variables:  map[string]string{"code": "1234", "name": "Ada"},
+	}
+	hash := func(t *testing.T, input NewMessage) string {
+		t.Helper()
+		h, err := requestHash(input)
+		if err != nil {
+			t.Fatalf("requestHash() error = %v", err)
+		}
+		return h
+	}
+
+	// Maps built in another order must not change the hash
+	reordered := base
+	reordered.Variables = make(map[string]string)
+	reordered.Variables["name"] = "Ada"
+	reordered.Variables["code"] = "1234"
+	if hash(t, base) != hash(t, reordered) {
+		t.Error("requestHash() differs for the same variables in another order")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*NewMessage)
+	}{
+		{name: "recipient", modify: func(m *NewMessage) { m.To = "+905551234568" }},
+		{name: "content", modify: func(m *NewMessage) { m.Content = "Hello" }},
+		{name: "variable", modify: func(m *NewMessage) { m.Variables = map[string]string{"code": "4321", "name": "Ada"} }},
+		{name: "template version", modify: func(m *NewMessage) { m.TemplateVersion = 2 }},
+		{name: "locale", modify: func(m *NewMessage) { m.Locale = "tr" }},
+		{name: "transliterate on", modify: func(m *NewMessage) { m.Transliterate = &yes }},
+		{name: "transliterate off", modify: func(m *NewMessage) { m.Transliterate = &no }},
+		{name: "quiet hours bypass", modify: func(m *NewMessage) { m.BypassQuietHours = true }},
+		{name: "priority", modify: func(m *NewMessage) { m.Priority = domain.PriorityBulk }},
+		{name: "callback url", modify: func(m *NewMessage) { m.CallbackURL = "https://example.com/status" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.modify(&changed)
+			if hash(t, base) == hash(t, changed) {
+				t.Errorf("requestHash() is the same with a different %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestCreateMessageIdempotent(t *testing.T) {
+	request := NewMessage{To: "+905551234567", Content: "Hello"}
+	requestHashValue, err := requestHash(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := &domain.Message{ID: uuid.New(), To: request.To, Content: request.Content, Status: domain.MessageStatusSent}
+	storedKey := &domain.IdempotencyKey{ClientID: "shop", Key: "order-1", RequestHash: requestHashValue, MessageID: original.ID}
+
+	tests := []struct {
+		name        string
+		input       NewMessage
+		idempotency Idempotency
+		stored      *domain.IdempotencyKey
+		racing      *domain.IdempotencyKey
+		wantErr     error
+		wantField   string
+	}{
+		{
+			name:        "missing client id",
+			input:       request,
+			idempotency: Idempotency{Key: "order-1"},
+			wantErr:     errors.ErrInvalidRequest,
+			wantField:   "X-Client-ID",
+		},
+		{
+			name:        "key too long",
+			input:       request,
+			idempotency: Idempotency{ClientID: "shop", Key: strings.Repeat("k", maxIdempotencyKeyLength+1)},
+			wantErr:     errors.ErrInvalidRequest,
+		},
+		{
+			name:        "client id too long",
+			input:       request,
+			idempotency: Idempotency{ClientID: strings.Repeat("c", maxClientIDLength+1), Key: "order-1"},
+			wantErr:     errors.ErrInvalidRequest,
+		},
+		{
+			name:        "retry replays the original",
+			input:       request,
+			idempotency: Idempotency{ClientID: "shop", Key: "order-1"},
+			stored:      storedKey,
+		},
+		{
+			name:        "key reused with another body",
+			input:       NewMessage{To: request.To, Content: "Hello again"},
+			idempotency: Idempotency{ClientID: "shop", Key: "order-1"},
+			stored:      storedKey,
+			wantErr:     errors.ErrIdempotencyKeyReused,
+		},
+		{
+			name:        "key reused with an invalid body",
+			input:       NewMessage{To: "not a number"},
+			idempotency: Idempotency{ClientID: "shop", Key: "order-1"},
+			stored:      storedKey,
+			wantErr:     errors.ErrIdempotencyKeyReused,
+		},
+		{
+			name:        "concurrent request with the same body",
+			input:       request,
+			idempotency: Idempotency{ClientID: "shop", Key: "order-1"},
+			racing:      storedKey,
+		},
+		{
+			name:        "concurrent request with another body",
+			input:       NewMessage{To: request.To, Content: "Hello again"},
+			idempotency: Idempotency{ClientID: "shop", Key: "order-1"},
+			racing:      storedKey,
+			wantErr:     errors.ErrIdempotencyKeyReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{
+				messages:        map[string]*domain.Message{original.ID.String(): original},
+				idempotencyKeys: make(map[[2]string]*domain.IdempotencyKey),
+				racingKey:       tt.racing,
+			}
+			if tt.stored != nil {
+				repo.idempotencyKeys[[2]string{tt.stored.ClientID, tt.stored.Key}] = tt.stored
+			}
+			s := &Service{
+				repo:        repo,
+				cfg:         &config.Config{DefaultRegion: "TR", IdempotencyTTL: time.Hour},
+				httpTimeout: time.Second,
+			}
+
+			msg, replayed, err := s.CreateMessageIdempotent(context.Background(), tt.input, tt.idempotency)
+			if tt.wantErr != nil {
+				if !stderrors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateMessageIdempotent() error = %v, want %v", err, tt.wantErr)
+				}
+				var validationErr *errors.ValidationError
+				if tt.wantField != "" && (!stderrors.As(err, &validationErr) || validationErr.Field != tt.wantField) {
+					t.Errorf("CreateMessageIdempotent() error = %v, want a validation error of %s", err, tt.wantField)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateMessageIdempotent() error = %v", err)
+			}
+			if !replayed || msg.ID != original.ID {
+				t.Errorf("CreateMessageIdempotent() = %v, replayed %v, want the original %v replayed", msg.ID, replayed, original.ID)
+			}
+		})
+	}
+}
+
+func TestResendMessageRefusesUnsentMessages(t *testing.T) {
+	messages := map[string]*domain.Message{}
+	for _, status := range []domain.MessageStatus{domain.MessageStatusPending, domain.MessageStatusFailed, domain.MessageStatusCancelled, domain.MessageStatusDuplicate} {
+		msg := &domain.Message{ID: uuid.New(), To: "+905551234567", Status: status}
+		messages[msg.ID.String()] = msg
+	}
+	// Nothing is stored, the fake repository would panic on the insert
+	s := &Service{repo: &fakeRepository{messages: messages}, cfg: &config.Config{}, httpTimeout: time.Second}
+
+	for id, msg := range messages {
+		if _, err := s.ResendMessage(context.Background(), id); !stderrors.Is(err, errors.ErrMessageNotSent) {
+			t.Errorf("ResendMessage(%s message) error = %v, want %v", msg.Status, err, errors.ErrMessageNotSent)
+		}
+	}
+
+	if _, err := s.ResendMessage(context.Background(), uuid.NewString()); !stderrors.Is(err, errors.ErrMessageNotFound) {
+		t.Errorf("ResendMessage(unknown) error = %v, want %v", err, errors.ErrMessageNotFound)
+	}
+	if _, err := s.ResendMessage(context.Background(), "42"); !stderrors.Is(err, errors.ErrInvalidRequest) {
+		t.Errorf("ResendMessage(42) error = %v, want %v", err, errors.ErrInvalidRequest)
+	}
+	if _, err := s.CancelMessage(context.Background(), "42"); !stderrors.Is(err, errors.ErrInvalidRequest) {
+		t.Errorf("CancelMessage(42) error = %v, want %v", err, errors.ErrInvalidRequest)
+	}
+}
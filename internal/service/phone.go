@@ -0,0 +1,38 @@
+package service
+
+import (
+	stderrors "errors"
+
+	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/phone"
+)
+
+// ParsePhone parses a phone number, numbers without a country code belong to the default region
+func (s *Service) ParsePhone(input, region string) (phone.Number, error) {
+	if region == "" {
+		region = s.cfg.DefaultRegion
+	}
+	number, err := phone.Parse(input, region)
+	if err != nil {
+		return phone.Number{}, phoneValidationError("phone", err)
+	}
+	return number, nil
+}
+
+// normalizePhone returns the number in E.164, field names the request field in the validation error
+func (s *Service) normalizePhone(field, input string) (string, error) {
+	e164, err := phone.Normalize(input, s.cfg.DefaultRegion)
+	if err != nil {
+		return "", phoneValidationError(field, err)
+	}
+	return e164, nil
+}
+
+// phoneValidationError turns a phone parse error into a structured validation error
+func phoneValidationError(field string, err error) error {
+	var parseErr *phone.ParseError
+	if stderrors.As(err, &parseErr) {
+		return &errors.ValidationError{Field: field, Code: parseErr.Code, Message: parseErr.Message}
+	}
+	return errors.Wrap(errors.ErrInvalidRequest, field+": "+err.Error())
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"insider-challenge/pkg/config"
+	"insider-challenge/pkg/errors"
+	"insider-challenge/pkg/phone"
+)
+
+// countryRateWindow length of the fixed window the country send rates are counted in
+const countryRateWindow = time.Minute
+
+// PolicyDecision is the outcome of the sending policy for a single message
+type PolicyDecision struct {
+	Blocked    bool
+	DeferUntil time.Time // Zero when the message may be sent now
+	Reason     string
+}
+
+// clockWindow is a daily window in a time zone, it wraps midnight when start is after end
+type clockWindow struct {
+	start    time.Duration // Offset from local midnight
+	end      time.Duration
+	location *time.Location
+}
+
+// CountryPolicy decides per destination country whether a message may be sent now
+type CountryPolicy struct {
+	allowed map[string]bool // Empty allows every country that is not blocked
+	blocked map[string]bool
+	rates   map[string]int64 // Messages per countryRateWindow
+	quiet   map[string]clockWindow
+}
+
+// NewCountryPolicy creates the country policy from the configuration, invalid entries are logged and ignored
+func NewCountryPolicy(cfg *config.Config) *CountryPolicy {
+	policy := &CountryPolicy{
+		allowed: make(map[string]bool, len(cfg.AllowedCountries)),
+		blocked: make(map[string]bool, len(cfg.BlockedCountries)),
+		rates:   make(map[string]int64, len(cfg.CountryRateLimits)),
+		quiet:   make(map[string]clockWindow, len(cfg.CountryQuietHours)),
+	}
+
+	for _, country := range cfg.AllowedCountries {
+		policy.allowed[strings.ToUpper(country)] = true
+	}
+	for _, country := range cfg.BlockedCountries {
+		policy.blocked[strings.ToUpper(country)] = true
+	}
+
+	for country, value := range cfg.CountryRateLimits {
+		rate, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || rate <= 0 {
+			log.Printf("Ignoring invalid COUNTRY_RATE_LIMITS entry %s:%s", country, value)
+			continue
+		}
+		policy.rates[strings.ToUpper(country)] = rate
+	}
+
+	for country, value := range cfg.CountryQuietHours {
+		country = strings.ToUpper(country)
+		timezone := cfg.CountryTimezones[country]
+		if timezone == "" {
+			timezone = phone.TimezoneOf(country)
+		}
+		hours, err := parseClockWindow(value, timezone)
+		if err != nil {
+			log.Printf("Ignoring COUNTRY_QUIET_HOURS entry of %s: %v", country, err)
+			continue
+		}
+		policy.quiet[country] = hours
+	}
+
+	return policy
+}
+
+// Block returns why messages to the country are not allowed, empty when they are
+func (cp *CountryPolicy) Block(country string) string {
+	switch {
+	case country == "" && len(cp.allowed) > 0:
+		return "destination country is unknown"
+	case cp.blocked[country]:
+		return "destination country " + country + " is blocked"
+	case len(cp.allowed) > 0 && !cp.allowed[country]:
+		return "destination country " + country + " is not allowed"
+	}
+	return ""
+}
+
+// Throttle decides whether a message to the country may be sent at now, it counts the send against the country rate when it may.
+// bypassQuietHours skips the quiet hours but never the rate limit.
+func (cp *CountryPolicy) Throttle(ctx context.Context, country string, now time.Time, bypassQuietHours bool) (PolicyDecision, error) {
+	if hours, ok := cp.quiet[country]; ok && !bypassQuietHours && hours.contains(now) {
+		return PolicyDecision{DeferUntil: hours.endAfter(now), Reason: "quiet hours in " + country}, nil
+	}
+
+	// The rate is checked last so deferred messages never use up the window
+	if rate, ok := cp.rates[country]; ok {
+		windowStart := now.Truncate(countryRateWindow)
+		count, err := config.IncrementCountryRate(ctx, country, windowStart, countryRateWindow)
+		if err != nil {
+			return PolicyDecision{}, errors.Wrap(errors.ErrStateStore, err.Error())
+		}
+		if count > rate {
+			return PolicyDecision{
+				DeferUntil: windowStart.Add(countryRateWindow),
+				Reason:     fmt.Sprintf("rate limit of %d messages per minute to %s", rate, country),
+			}, nil
+		}
+	}
+
+	return PolicyDecision{}, nil
+}
+
+// parseClockWindow parses a "22:00-08:00" window of the time zone
+func parseClockWindow(value, timezone string) (clockWindow, error) {
+	if timezone == "" {
+		return clockWindow{}, fmt.Errorf("no time zone")
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return clockWindow{}, fmt.Errorf("invalid time zone %q", timezone)
+	}
+
+	startValue, endValue, found := strings.Cut(value, "-")
+	if !found {
+		return clockWindow{}, fmt.Errorf("invalid window %q, expected HH:MM-HH:MM", value)
+	}
+	start, err := parseClock(startValue)
+	if err != nil {
+		return clockWindow{}, err
+	}
+	end, err := parseClock(endValue)
+	if err != nil {
+		return clockWindow{}, err
+	}
+	if start == end {
+		return clockWindow{}, fmt.Errorf("empty window %q", value)
+	}
+	return clockWindow{start: start, end: end, location: location}, nil
+}
+
+// parseClock parses HH:MM into an offset from midnight
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// contains reports whether the local time of t falls in the window
+func (w clockWindow) contains(t time.Time) bool {
+	local := t.In(w.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// startAfter returns the first start of the window after t
+func (w clockWindow) startAfter(t time.Time) time.Time {
+	return w.nextClock(t, w.start)
+}
+
+// endAfter returns the first end of the window after t
+func (w clockWindow) endAfter(t time.Time) time.Time {
+	return w.nextClock(t, w.end)
+}
+
+// nextClock returns the first time after t the local clock shows the offset
+func (w clockWindow) nextClock(t time.Time, offset time.Duration) time.Time {
+	local := t.In(w.location)
+	hour, minute := int(offset/time.Hour), int(offset%time.Hour/time.Minute)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, w.location)
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, w.location)
+	}
+	return next
+}
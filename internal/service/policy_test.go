@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/config"
+)
+
+func TestParseClockWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		timezone string
+		start    time.Duration
+		end      time.Duration
+		wantErr  bool
+	}{
+		{name: "same day", value: "12:00-14:30", timezone: "Europe/Istanbul", start: 12 * time.Hour, end: 14*time.Hour + 30*time.Minute},
+		{name: "across midnight", value: "22:00 - 08:00", timezone: "Europe/Istanbul", start: 22 * time.Hour, end: 8 * time.Hour},
+		{name: "no time zone", value: "22:00-08:00", wantErr: true},
+		{name: "invalid time zone", value: "22:00-08:00", timezone: "Europe/Nowhere", wantErr: true},
+		{name: "missing end", value: "22:00", timezone: "Europe/Istanbul", wantErr: true},
+		{name: "invalid time", value: "25:00-08:00", timezone: "Europe/Istanbul", wantErr: true},
+		{name: "empty window", value: "08:00-08:00", timezone: "Europe/Istanbul", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseClockWindow(tt.value, tt.timezone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseClockWindow(%q, %q) error = %v, wantErr %v", tt.value, tt.timezone, err, tt.wantErr)
+			}
+			if got.start != tt.start || got.end != tt.end {
+				t.Errorf("parseClockWindow(%q, %q) = %v-%v, want %v-%v", tt.value, tt.timezone, got.start, got.end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestCountryPolicyBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		country string
+		blocked bool
+	}{
+		{name: "no lists", country: "TR"},
+		{name: "no lists and unknown country", country: ""},
+		{name: "blocked", cfg: config.Config{BlockedCountries: []string{"ru"}}, country: "RU", blocked: true},
+		{name: "not blocked", cfg: config.Config{BlockedCountries: []string{"RU"}}, country: "TR"},
+		{name: "allowed", cfg: config.Config{AllowedCountries: []string{"tr", "DE"}}, country: "TR"},
+		{name: "not allowed", cfg: config.Config{AllowedCountries: []string{"TR"}}, country: "DE", blocked: true},
+		{name: "unknown country with allow list", cfg: config.Config{AllowedCountries: []string{"TR"}}, country: "", blocked: true},
+		{name: "block list wins", cfg: config.Config{AllowedCountries: []string{"TR"}, BlockedCountries: []string{"TR"}}, country: "TR", blocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := NewCountryPolicy(&tt.cfg).Block(tt.country)
+			if (reason != "") != tt.blocked {
+				t.Errorf("Block(%q) = %q, want blocked %v", tt.country, reason, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestCountryPolicyQuietHours(t *testing.T) {
+	// Europe/Istanbul is UTC+3 all year
+	policy := NewCountryPolicy(&config.Config{
+		CountryQuietHours: map[string]string{"tr": "22:00-08:00", "DE": "12:00-14:00"},
+		CountryTimezones:  map[string]string{"DE": "Europe/Istanbul"},
+	})
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		country    string
+		now        time.Time
+		bypass     bool
+		deferUntil time.Time
+	}{
+		{name: "before quiet hours", country: "TR", now: at(1, 18, 59)},
+		{name: "quiet hours start", country: "TR", now: at(1, 19, 0), deferUntil: at(2, 5, 0)},
+		{name: "before midnight", country: "TR", now: at(1, 20, 30), deferUntil: at(2, 5, 0)},
+		{name: "after midnight", country: "TR", now: at(2, 2, 0), deferUntil: at(2, 5, 0)},
+		{name: "quiet hours end", country: "TR", now: at(2, 5, 0)},
+		{name: "bypass", country: "TR", now: at(1, 20, 30), bypass: true},
+		{name: "country without quiet hours", country: "GB", now: at(1, 20, 30)},
+		{name: "configured time zone", country: "DE", now: at(1, 9, 30), deferUntil: at(1, 11, 0)},
+		{name: "after same day window", country: "DE", now: at(1, 11, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := policy.Throttle(context.Background(), tt.country, tt.now, tt.bypass)
+			if err != nil {
+				t.Fatalf("Throttle() error = %v", err)
+			}
+			if decision.Blocked || !decision.DeferUntil.Equal(tt.deferUntil) {
+				t.Errorf("Throttle() = %+v, want DeferUntil %v", decision, tt.deferUntil)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"strings"
+	"time"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// ReceiptInput holds a delivery receipt reported by a provider
+type ReceiptInput struct {
+	ProviderMessageID string
+	Status            domain.DeliveryStatus
+	OccurredAt        time.Time
+}
+
+// RecordReceipt authenticates the provider and applies the receipt to the message it reports on.
+// Receipts for provider message ids we have not committed yet are kept and applied once the message is marked sent.
+func (s *Service) RecordReceipt(ctx context.Context, provider, token string, input ReceiptInput) (*domain.DeliveryReceipt, bool, error) {
+	if err := s.authenticateProvider(provider, token); err != nil {
+		return nil, false, err
+	}
+
+	input.ProviderMessageID = strings.TrimSpace(input.ProviderMessageID)
+	if input.ProviderMessageID == "" {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, "messageId is required")
+	}
+	if !input.Status.IsValid() {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, "invalid delivery status: "+string(input.Status))
+	}
+	if input.OccurredAt.IsZero() {
+		input.OccurredAt = time.Now()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	receipt := &domain.DeliveryReceipt{
+		Provider:          provider,
+		ProviderMessageID: input.ProviderMessageID,
+		Status:            input.Status,
+		OccurredAt:        input.OccurredAt,
+	}
+	msg, err := s.repo.RecordDeliveryReceipt(ctx, receipt)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "record delivery receipt")
+	}
+
+	if msg == nil {
+		log.Printf("Receipt for unknown %s message %s kept until the message is marked sent", provider, input.ProviderMessageID)
+		return receipt, false, nil
+	}
+	return receipt, true, nil
+}
+
+// authenticateProvider checks the token against the one configured for the provider
+func (s *Service) authenticateProvider(provider, token string) error {
+	expected, ok := s.cfg.ProviderTokens[provider]
+	if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(token)) != 1 {
+		return errors.Wrap(errors.ErrUnauthorized, "provider: "+provider)
+	}
+	return nil
+}
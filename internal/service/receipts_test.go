@@ -0,0 +1,38 @@
+package service
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"insider-challenge/pkg/config"
+	"insider-challenge/pkg/errors"
+)
+
+func TestAuthenticateProvider(t *testing.T) {
+	s := &Service{cfg: &config.Config{ProviderTokens: map[string]string{"webhook": "s3cret", "other": "t0ken"}}}
+
+	tests := []struct {
+		name     string
+		provider string
+		token    string
+		wantErr  bool
+	}{
+		{name: "valid token", provider: "webhook", token: "s3cret"},
+		{name: "wrong token", provider: "webhook", token: "s3cre", wantErr: true},
+		{name: "token of another provider", provider: "webhook", token: "t0ken", wantErr: true},
+		{name: "unknown provider", provider: "unknown", token: "s3cret", wantErr: true},
+		{name: "empty token", provider: "webhook", token: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.authenticateProvider(tt.provider, tt.token)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("authenticateProvider() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !stderrors.Is(err, errors.ErrUnauthorized) {
+				t.Errorf("authenticateProvider() error = %v, want %v", err, errors.ErrUnauthorized)
+			}
+		})
+	}
+}
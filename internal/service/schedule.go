@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"insider-challenge/internal/repository"
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// scheduleLookahead days searched for the next sending opportunity before giving up
+const scheduleLookahead = 366
+
+// maxBlackoutDescriptionLength matches the size of the description column
+const maxBlackoutDescriptionLength = 255
+
+// SendingSchedule defers messages outside the daily sending window and on blackout dates
+type SendingSchedule struct {
+	repo     repository.Repository
+	window   *clockWindow // nil sends around the clock
+	location *time.Location
+
+	// blackoutTTL how long the blackout calendar is cached, other replicas pick up changes within it
+	blackoutTTL time.Duration
+
+	blackoutsLock     sync.Mutex
+	blackouts         map[string]bool
+	blackoutsLoadedAt time.Time
+}
+
+// NewSendingSchedule creates the sending schedule from the configuration, an invalid window is logged and ignored
+func NewSendingSchedule(repo repository.Repository, cfg *config.Config) *SendingSchedule {
+	location, err := time.LoadLocation(cfg.SendingTimezone)
+	if err != nil {
+		log.Printf("Unknown SENDING_TIMEZONE %q, using UTC", cfg.SendingTimezone)
+		location = time.UTC
+	}
+
+	schedule := &SendingSchedule{
+		repo:        repo,
+		location:    location,
+		blackoutTTL: 1 * time.Minute,
+	}
+	if cfg.SendingWindow != "" {
+		window, err := parseClockWindow(cfg.SendingWindow, location.String())
+		if err != nil {
+			log.Printf("Ignoring SENDING_WINDOW: %v", err)
+		} else {
+			schedule.window = &window
+		}
+	}
+	return schedule
+}
+
+// NextOpen returns when a message may be sent next and why it has to wait, zero when it may be sent at now
+func (ss *SendingSchedule) NextOpen(ctx context.Context, now time.Time) (time.Time, string, error) {
+	blackouts, err := ss.loadBlackouts(ctx)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+
+	candidate := now.In(ss.location)
+	reason := ""
+	for day := 0; day <= scheduleLookahead; day++ {
+		if ss.window != nil && !ss.window.contains(candidate) {
+			candidate = ss.window.startAfter(candidate)
+			if reason == "" {
+				reason = "outside the sending window"
+			}
+		}
+
+		date := candidate.Format(domain.BlackoutDateLayout)
+		if !blackouts[date] {
+			if reason == "" {
+				return time.Time{}, "", nil
+			}
+			return candidate, reason, nil
+		}
+
+		if reason == "" {
+			reason = "blackout date " + date
+		}
+		candidate = time.Date(candidate.Year(), candidate.Month(), candidate.Day()+1, 0, 0, 0, 0, ss.location)
+	}
+	return time.Time{}, "", errors.Wrap(errors.ErrConfiguration, "no sending day within a year")
+}
+
+// Today returns the current date in the sending time zone
+func (ss *SendingSchedule) Today() string {
+	return time.Now().In(ss.location).Format(domain.BlackoutDateLayout)
+}
+
+// loadBlackouts returns the upcoming blackout dates, reloading them once the cache expired
+func (ss *SendingSchedule) loadBlackouts(ctx context.Context) (map[string]bool, error) {
+	ss.blackoutsLock.Lock()
+	defer ss.blackoutsLock.Unlock()
+
+	if ss.blackouts != nil && time.Since(ss.blackoutsLoadedAt) < ss.blackoutTTL {
+		return ss.blackouts, nil
+	}
+
+	entries, err := ss.repo.ListBlackoutDates(ctx, ss.Today())
+	if err != nil {
+		return nil, errors.Wrap(err, "load blackout dates")
+	}
+
+	blackouts := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		blackouts[entry.Date] = true
+	}
+	ss.blackouts = blackouts
+	ss.blackoutsLoadedAt = time.Now()
+	return blackouts, nil
+}
+
+// invalidate drops the cached blackout calendar of this replica
+func (ss *SendingSchedule) invalidate() {
+	ss.blackoutsLock.Lock()
+	ss.blackouts = nil
+	ss.blackoutsLock.Unlock()
+}
+
+// BlackoutInput holds a blackout date to be added
+type BlackoutInput struct {
+	Date        string
+	Description string
+}
+
+// AddBlackoutDate adds a date to the blackout calendar, an existing date is replaced
+func (s *Service) AddBlackoutDate(ctx context.Context, input BlackoutInput) (*domain.BlackoutDate, error) {
+	date, err := parseBlackoutDate(input.Date)
+	if err != nil {
+		return nil, err
+	}
+	if utf8.RuneCountInString(input.Description) > maxBlackoutDescriptionLength {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "description exceeds 255 characters")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	now := time.Now()
+	blackout := &domain.BlackoutDate{
+		Date:        date,
+		Description: strings.TrimSpace(input.Description),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.UpsertBlackoutDate(ctx, blackout); err != nil {
+		return nil, errors.Wrap(err, "add blackout date")
+	}
+
+	s.schedule.invalidate()
+	return blackout, nil
+}
+
+// ListBlackoutDates retrieves the blackout dates from today on
+func (s *Service) ListBlackoutDates(ctx context.Context) ([]domain.BlackoutDate, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	blackouts, err := s.repo.ListBlackoutDates(ctx, s.schedule.Today())
+	if err != nil {
+		return nil, errors.Wrap(err, "list blackout dates")
+	}
+	return blackouts, nil
+}
+
+// DeleteBlackoutDate removes a date from the blackout calendar
+func (s *Service) DeleteBlackoutDate(ctx context.Context, date string) error {
+	date, err := parseBlackoutDate(date)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if err := s.repo.DeleteBlackoutDate(ctx, date); err != nil {
+		return errors.Wrap(err, "delete blackout date")
+	}
+
+	s.schedule.invalidate()
+	return nil
+}
+
+// parseBlackoutDate validates a YYYY-MM-DD date
+func parseBlackoutDate(value string) (string, error) {
+	date, err := time.Parse(domain.BlackoutDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return "", errors.Wrap(errors.ErrInvalidRequest, "invalid date, expected YYYY-MM-DD: "+value)
+	}
+	return date.Format(domain.BlackoutDateLayout), nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+)
+
+func TestSendingScheduleNextOpen(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, istanbul)
+	}
+
+	tests := []struct {
+		name      string
+		window    string
+		blackouts []string
+		now       time.Time
+		want      time.Time
+		reason    string
+	}{
+		{name: "around the clock", now: at(2, 3, 0)},
+		{name: "inside the window", window: "09:00-21:00", now: at(2, 9, 0)},
+		{name: "before the window", window: "09:00-21:00", now: at(2, 5, 0), want: at(2, 9, 0), reason: "outside the sending window"},
+		{name: "window end", window: "09:00-21:00", now: at(2, 21, 0), want: at(3, 9, 0), reason: "outside the sending window"},
+		{name: "inside a window across midnight", window: "22:00-02:00", now: at(3, 1, 30)},
+		{name: "outside a window across midnight", window: "22:00-02:00", now: at(2, 12, 0), want: at(2, 22, 0), reason: "outside the sending window"},
+		{name: "blackout date", blackouts: []string{"2025-06-02"}, now: at(2, 12, 0), want: at(3, 0, 0), reason: "blackout date 2025-06-02"},
+		{name: "consecutive blackout dates", blackouts: []string{"2025-06-02", "2025-06-03"}, now: at(2, 12, 0), want: at(4, 0, 0), reason: "blackout date 2025-06-02"},
+		{name: "blackout date in the window", window: "09:00-21:00", blackouts: []string{"2025-06-02"}, now: at(2, 12, 0), want: at(3, 9, 0), reason: "blackout date 2025-06-02"},
+		{name: "window opens on a blackout date", window: "09:00-21:00", blackouts: []string{"2025-06-03"}, now: at(2, 22, 0), want: at(4, 9, 0), reason: "outside the sending window"},
+		{name: "blackout date of another day", window: "09:00-21:00", blackouts: []string{"2025-06-03"}, now: at(2, 12, 0)},
+		{name: "time zone of the schedule", window: "09:00-21:00", now: time.Date(2025, 6, 2, 5, 30, 0, 0, time.UTC), want: at(2, 9, 0), reason: "outside the sending window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{}
+			for _, date := range tt.blackouts {
+				repo.blackouts = append(repo.blackouts, domain.BlackoutDate{Date: date})
+			}
+			schedule := NewSendingSchedule(repo, &config.Config{SendingWindow: tt.window, SendingTimezone: "Europe/Istanbul"})
+
+			got, reason, err := schedule.NextOpen(context.Background(), tt.now)
+			if err != nil {
+				t.Fatalf("NextOpen() error = %v", err)
+			}
+			if !got.Equal(tt.want) || reason != tt.reason {
+				t.Errorf("NextOpen() = %v, %q, want %v, %q", got, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+func TestSendingScheduleNextOpenWithoutSendingDay(t *testing.T) {
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{}
+	for day := 0; day <= scheduleLookahead+1; day++ {
+		repo.blackouts = append(repo.blackouts, domain.BlackoutDate{Date: now.AddDate(0, 0, day).Format(domain.BlackoutDateLayout)})
+	}
+	schedule := NewSendingSchedule(repo, &config.Config{SendingTimezone: "UTC"})
+
+	if _, _, err := schedule.NextOpen(context.Background(), now); err == nil || !strings.Contains(err.Error(), "no sending day") {
+		t.Errorf("NextOpen() error = %v, want no sending day", err)
+	}
+}
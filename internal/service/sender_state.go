@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// senderStateResyncInterval how often the persisted state is re-read in case a broadcast was missed
+const senderStateResyncInterval = 30 * time.Second
+
+// SetSenderState persists the desired sender state, broadcasts it to every replica and applies it locally
+func (s *Service) SetSenderState(ctx context.Context, state domain.SenderState) error {
+	if !state.IsValid() {
+		return errors.Wrap(errors.ErrInvalidRequest, "unknown sender state: "+string(state))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if err := s.repo.SetSenderState(ctx, state); err != nil {
+		return errors.Wrap(err, "set sender state")
+	}
+	if err := config.PublishSenderState(ctx, string(state)); err != nil {
+		// The state is stored, the other replicas apply it with their next resync
+		log.Printf("Failed to broadcast sender state %s: %v", state, err)
+	}
+
+	s.applySenderState(state)
+	return nil
+}
+
+// GetSenderState retrieves the desired sender state, SENDER_AUTO_START until a state was ever stored
+func (s *Service) GetSenderState(ctx context.Context) (domain.SenderState, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	state, err := s.repo.GetSenderState(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "get sender state")
+	}
+	if state == "" {
+		if s.cfg.SenderAutoStart {
+			return domain.SenderStateRunning, nil
+		}
+		return domain.SenderStateStopped, nil
+	}
+	if !state.IsValid() {
+		return "", errors.Wrap(errors.ErrDatabaseOperation, "invalid persisted sender state: "+string(state))
+	}
+	return state, nil
+}
+
+// RestoreSenderState applies the persisted sender state on boot
+func (s *Service) RestoreSenderState(ctx context.Context) error {
+	state, err := s.GetSenderState(ctx)
+	if err != nil {
+		return errors.Wrap(err, "restore sender state")
+	}
+
+	log.Printf("Restoring message sender state: %s", state)
+	s.applySenderState(state)
+	return nil
+}
+
+// WatchSenderState applies sender state changes broadcast by other replicas until ctx is done
+func (s *Service) WatchSenderState(ctx context.Context) {
+	pubsub := config.SubscribeSenderState(ctx)
+	defer pubsub.Close()
+
+	resync := time.NewTicker(senderStateResyncInterval)
+	defer resync.Stop()
+
+	changes := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-changes:
+			if !ok {
+				return
+			}
+			state := domain.SenderState(msg.Payload)
+			if !state.IsValid() {
+				log.Printf("Ignoring invalid sender state broadcast: %q", msg.Payload)
+				continue
+			}
+			s.applySenderState(state)
+		case <-resync.C:
+			s.resyncSenderState(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resyncSenderState applies the stored state in case a broadcast was missed. Without a stored state the sender is left as
+// it is, SENDER_AUTO_START only decides on boot so a lost state can never silently restart a stopped fleet.
+func (s *Service) resyncSenderState(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	state, err := s.repo.GetSenderState(ctx)
+	switch {
+	case err != nil:
+		log.Printf("Failed to resync sender state: %v", err)
+	case state == "":
+		log.Println("No sender state stored, keeping the local message sender as it is")
+	case !state.IsValid():
+		log.Printf("Ignoring invalid persisted sender state: %q", state)
+	default:
+		s.applySenderState(state)
+	}
+}
+
+// applySenderState starts or stops the local message sender to match the desired state. It is called from requests,
+// broadcasts and the resync ticker, so the transitions are serialized and a Start never overlaps a Stop still waiting for its cycle.
+func (s *Service) applySenderState(state domain.SenderState) {
+	s.senderStateLock.Lock()
+	defer s.senderStateLock.Unlock()
+
+	switch state {
+	case domain.SenderStateRunning:
+		s.messageSender.Start()
+	case domain.SenderStateStopped:
+		s.messageSender.Stop()
+	}
+}
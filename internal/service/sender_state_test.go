@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// newStateService builds a service around a stopped local sender and the stored state
+func newStateService(t *testing.T, stored domain.SenderState, autoStart bool) *Service {
+	t.Helper()
+	repo := &fakeRepository{senderState: stored}
+	cfg := &config.Config{SenderAutoStart: autoStart}
+	s := &Service{
+		repo:          repo,
+		cfg:           cfg,
+		messageSender: NewMessageSender(repo, cfg, nil, nil, nil, nil, nil),
+		httpTimeout:   time.Second,
+	}
+	t.Cleanup(s.messageSender.Stop)
+	return s
+}
+
+func TestGetSenderState(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    domain.SenderState
+		autoStart bool
+		want      domain.SenderState
+		wantErr   bool
+	}{
+		{name: "never stored with auto start", autoStart: true, want: domain.SenderStateRunning},
+		{name: "never stored without auto start", want: domain.SenderStateStopped},
+		{name: "stored stopped wins over auto start", stored: domain.SenderStateStopped, autoStart: true, want: domain.SenderStateStopped},
+		{name: "stored running", stored: domain.SenderStateRunning, want: domain.SenderStateRunning},
+		{name: "invalid stored state", stored: "paused", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newStateService(t, tt.stored, tt.autoStart).GetSenderState(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetSenderState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetSenderState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResyncSenderState(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  domain.SenderState
+		running bool
+		want    bool
+	}{
+		// A lost state must never restart a stopped fleet, even with SENDER_AUTO_START
+		{name: "missing state keeps a stopped sender", running: false, want: false},
+		{name: "missing state keeps a running sender", running: true, want: true},
+		{name: "stored stop", stored: domain.SenderStateStopped, running: true, want: false},
+		{name: "stored start", stored: domain.SenderStateRunning, running: false, want: true},
+		{name: "invalid state is ignored", stored: "paused", running: false, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStateService(t, tt.stored, true)
+			if tt.running {
+				s.messageSender.Start()
+			}
+
+			s.resyncSenderState(context.Background())
+
+			if got := s.messageSender.IsRunning(); got != tt.want {
+				t.Errorf("running after resync = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManualSendsWhileStopped(t *testing.T) {
+	s := newStateService(t, domain.SenderStateStopped, true)
+
+	if _, err := s.TriggerMessageSender(context.Background()); !stderrors.Is(err, errors.ErrSenderStopped) {
+		t.Errorf("TriggerMessageSender() error = %v, want %v", err, errors.ErrSenderStopped)
+	}
+	if _, _, err := s.SendMessageNow(context.Background(), uuid.NewString()); !stderrors.Is(err, errors.ErrSenderStopped) {
+		t.Errorf("SendMessageNow() error = %v, want %v", err, errors.ErrSenderStopped)
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"log"
+	"sync"
 	"time"
 
 	"insider-challenge/internal/repository"
@@ -15,20 +17,131 @@ type Service struct {
 	repo          repository.Repository
 	cfg           *config.Config
 	messageSender *MessageSender
+	leader        *LeaderElector
+	events        *EventHub
+	callbacks     *CallbackDispatcher
+	suppressions  *SuppressionList
+	schedule      *SendingSchedule
 	httpTimeout   time.Duration
+
+	// senderStateLock serializes the start and stop transitions of the local sender
+	senderStateLock sync.Mutex
 }
 
 // New creates a new service instance
 func New(repo repository.Repository, cfg *config.Config) *Service {
-	messageSender := NewMessageSender(repo, cfg)
+	events := NewEventHub(cfg)
+	callbacks := NewCallbackDispatcher(repo, cfg)
+	suppressions := NewSuppressionList(repo)
+	schedule := NewSendingSchedule(repo, cfg)
+	messageSender := NewMessageSender(repo, cfg, events, callbacks, suppressions, NewCountryPolicy(cfg), schedule)
+
+	if !domain.SegmentPolicy(cfg.SegmentPolicy).IsValid() {
+		log.Printf("Unknown SEGMENT_POLICY %q, allowing multipart messages", cfg.SegmentPolicy)
+	}
+	if !domain.Priority(cfg.DefaultPriority).IsValid() {
+		log.Printf("Unknown DEFAULT_PRIORITY %q, using transactional", cfg.DefaultPriority)
+	}
+
+	var leader *LeaderElector
+	if cfg.LeaderElectionEnabled {
+		leader = NewLeaderElector(cfg)
+		messageSender.SetLeaderElector(leader)
+	}
+
 	return &Service{
 		repo:          repo,
 		cfg:           cfg,
 		messageSender: messageSender,
+		leader:        leader,
+		events:        events,
+		callbacks:     callbacks,
+		suppressions:  suppressions,
+		schedule:      schedule,
 		httpTimeout:   10 * time.Second,
 	}
 }
 
+// ListenForNewMessages wakes the message sender as soon as messages are inserted until ctx is done
+func (s *Service) ListenForNewMessages(ctx context.Context) {
+	if !s.cfg.NotifyEnabled {
+		return
+	}
+	repository.NewListener(s.cfg, repository.MessageCreatedChannel).Listen(ctx, s.messageSender.Wake)
+}
+
+// RunEventHub relays the lifecycle events of every replica to the local subscribers until ctx is done
+func (s *Service) RunEventHub(ctx context.Context) {
+	s.events.Run(ctx)
+}
+
+// SubscribeEvents registers a subscriber for live lifecycle events
+func (s *Service) SubscribeEvents() *EventSubscription {
+	return s.events.Subscribe()
+}
+
+// UnsubscribeEvents releases a lifecycle event subscriber
+func (s *Service) UnsubscribeEvents(sub *EventSubscription) {
+	s.events.Unsubscribe(sub)
+}
+
+// ReplayEvents retrieves the buffered lifecycle events published after lastID
+func (s *Service) ReplayEvents(ctx context.Context, lastID string) ([]domain.LifecycleEvent, error) {
+	if !IsValidEventID(lastID) {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "invalid event ID: "+lastID)
+	}
+
+	events, err := s.events.Replay(ctx, lastID)
+	if err != nil {
+		return nil, errors.Wrap(err, "replay events")
+	}
+	return events, nil
+}
+
+// StartCallbackDispatcher starts delivering the queued status callbacks
+func (s *Service) StartCallbackDispatcher() {
+	s.callbacks.Start()
+}
+
+// StopCallbackDispatcher stops delivering status callbacks gracefully
+func (s *Service) StopCallbackDispatcher() {
+	s.callbacks.Stop()
+}
+
+// GetMessageCallbacks retrieves the status callbacks of a message with their delivery log
+func (s *Service) GetMessageCallbacks(ctx context.Context, messageID string) ([]domain.CallbackDelivery, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if _, err := s.repo.GetMessageByIDUnscoped(ctx, messageID); err != nil {
+		return nil, errors.Wrap(err, "get message callbacks")
+	}
+
+	deliveries, err := s.repo.GetCallbackDeliveries(ctx, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get message callbacks")
+	}
+	return deliveries, nil
+}
+
+// StartLeaderElection starts campaigning for the sender leadership when it is enabled
+func (s *Service) StartLeaderElection() {
+	if s.leader != nil {
+		s.leader.Start()
+	}
+}
+
+// StopLeaderElection releases the sender leadership so another replica can take over
+func (s *Service) StopLeaderElection() {
+	if s.leader != nil {
+		s.leader.Stop()
+	}
+}
+
 // StartMessageSender starts the message sender service
 func (s *Service) StartMessageSender() {
 	s.messageSender.Start()
@@ -39,19 +152,172 @@ func (s *Service) StopMessageSender() {
 	s.messageSender.Stop()
 }
 
-// GetSentMessages retrieves all sent messages from the repository
-func (s *Service) GetSentMessages(page, pageSize int) ([]domain.Message, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), s.httpTimeout)
+// GetSentMessages retrieves a page of sent messages from the repository, hasMore reports a following page
+func (s *Service) GetSentMessages(ctx context.Context, filter domain.MessageFilter, page, pageSize int) ([]domain.Message, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	// One extra row tells whether another page exists without counting
+	messages, err := s.repo.GetSentMessages(ctx, filter, (page-1)*pageSize, pageSize+1)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "get sent messages")
+	}
+	messages, hasMore := trimPage(messages, pageSize, false)
+	return messages, hasMore, nil
+}
+
+// GetSentMessagesByCursor retrieves the sent messages next to the cursor, hasMore reports another page in the cursor direction
+func (s *Service) GetSentMessagesByCursor(ctx context.Context, filter domain.MessageFilter, cursor domain.MessageCursor, pageSize int) ([]domain.Message, bool, error) {
+	if filter.SortBy != domain.SortBySentAt {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, "cursor pagination requires sorting by sent_at")
+	}
+	if filter.SortAsc != cursor.SortAsc {
+		return nil, false, errors.Wrap(errors.ErrInvalidRequest, "cursor was issued for a different sort order")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	messages, err := s.repo.GetSentMessagesByCursor(ctx, filter, cursor, pageSize+1)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "get sent messages by cursor")
+	}
+	messages, hasMore := trimPage(messages, pageSize, cursor.Backward)
+	return messages, hasMore, nil
+}
+
+// CountSentMessages counts the sent messages matching the filter, estimate uses planner statistics instead of COUNT(*)
+func (s *Service) CountSentMessages(ctx context.Context, filter domain.MessageFilter, estimate bool) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
 	defer cancel()
 
-	messages, total, err := s.repo.GetSentMessages(ctx, page, pageSize)
+	var total int64
+	var err error
+	if estimate {
+		total, err = s.repo.EstimateSentMessages(ctx, filter)
+	} else {
+		total, err = s.repo.CountSentMessages(ctx, filter)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "count sent messages")
+	}
+	return total, nil
+}
+
+// ExportSentMessages streams every sent message matching the filter to fn, resuming after the cursor when given
+func (s *Service) ExportSentMessages(ctx context.Context, filter domain.MessageFilter, after *domain.MessageCursor, fn func(domain.Message) error) error {
+	if after != nil && after.SortAsc != filter.SortAsc {
+		return errors.Wrap(errors.ErrInvalidRequest, "cursor was issued for a different sort order")
+	}
+
+	if err := s.repo.StreamSentMessages(ctx, filter, after, fn); err != nil {
+		return errors.Wrap(err, "export sent messages")
+	}
+	return nil
+}
+
+// trimPage drops the look-ahead row, which is the first row when paging backward
+func trimPage(messages []domain.Message, pageSize int, backward bool) ([]domain.Message, bool) {
+	if len(messages) <= pageSize {
+		return messages, false
+	}
+	if backward {
+		return messages[1:], true
+	}
+	return messages[:pageSize], true
+}
+
+// TriggerMessageSender runs one sending cycle immediately, refused while the fleet is stopped
+func (s *Service) TriggerMessageSender(ctx context.Context) (CycleResult, error) {
+	if err := s.requireSenderRunning(ctx); err != nil {
+		return CycleResult{}, errors.Wrap(err, "trigger message sender")
+	}
+
+	result, err := s.messageSender.Trigger()
 	if err != nil {
-		return nil, 0, errors.Wrap(err, "get sent messages")
+		return CycleResult{}, errors.Wrap(err, "trigger message sender")
 	}
-	return messages, total, nil
+	return result, nil
+}
+
+// SendMessageNow sends a single pending message synchronously and returns the webhook result, refused while the fleet is stopped
+func (s *Service) SendMessageNow(ctx context.Context, messageID string) (*domain.Message, WebhookResponse, error) {
+	if err := validateMessageID(messageID); err != nil {
+		return nil, WebhookResponse{}, err
+	}
+	if err := s.requireSenderRunning(ctx); err != nil {
+		return nil, WebhookResponse{}, errors.Wrap(err, "send message")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	msg, response, err := s.messageSender.SendOne(ctx, messageID)
+	if err != nil {
+		return nil, WebhookResponse{}, errors.Wrap(err, "send message")
+	}
+	return msg, response, nil
+}
+
+// requireSenderRunning refuses manual sends while the desired sender state is stopped, an operator's /stop holds for
+// every way of sending
+func (s *Service) requireSenderRunning(ctx context.Context) error {
+	state, err := s.GetSenderState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == domain.SenderStateStopped {
+		return errors.ErrSenderStopped
+	}
+	return nil
 }
 
 // IsRunning returns whether the message sender is currently running
 func (s *Service) IsRunning() bool {
 	return s.messageSender.IsRunning()
 }
+
+// SenderStatus describes the sender on this replica and the fleet wide state
+type SenderStatus struct {
+	InstanceID            string
+	Running               bool
+	DesiredState          domain.SenderState
+	LeaderElectionEnabled bool
+	Leader                string
+	IsLeader              bool
+	QueueDepth            map[domain.Priority]int64 // Pending messages per lane
+}
+
+// GetSenderStatus retrieves the sender status of this replica and the current leader
+func (s *Service) GetSenderStatus(ctx context.Context) (SenderStatus, error) {
+	status := SenderStatus{
+		InstanceID:            s.cfg.InstanceID,
+		Running:               s.messageSender.IsRunning(),
+		LeaderElectionEnabled: s.leader != nil,
+	}
+
+	desiredState, err := s.GetSenderState(ctx)
+	if err != nil {
+		return SenderStatus{}, errors.Wrap(err, "get sender status")
+	}
+	status.DesiredState = desiredState
+
+	queueCtx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+	queueDepth, err := s.repo.CountPendingMessages(queueCtx)
+	if err != nil {
+		return SenderStatus{}, errors.Wrap(err, "get queue depth")
+	}
+	status.QueueDepth = queueDepth
+
+	if s.leader != nil {
+		leader, err := s.leader.Leader(ctx)
+		if err != nil {
+			return SenderStatus{}, errors.Wrap(err, "get sender leader")
+		}
+		status.Leader = leader
+		status.IsLeader = s.leader.IsLeader()
+	}
+
+	return status, nil
+}
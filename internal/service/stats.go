@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+const (
+	// maxStatsBuckets upper bound of time series points in a single response
+	maxStatsBuckets = 1500
+
+	// topFailureReasons number of failure reasons returned in the statistics
+	topFailureReasons = 10
+)
+
+// statsIntervals maps the supported time series intervals to their length
+var statsIntervals = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// latencyPercentiles reported in the statistics
+var latencyPercentiles = []int{50, 90, 95, 99}
+
+// Stats summarizes the deliveries of a time range
+type Stats struct {
+	Statuses    map[domain.MessageStatus]int64 // Messages created in the range by their current status
+	Sent        int64                          // Attempts accepted by the webhook
+	Failed      int64                          // Failed attempts, retried ones included
+	Dead        int64                          // Failed attempts after which the message was given up
+	SuccessRate float64
+	AvgLatency  float64
+	Latency     map[int]int // Percentile to upper bound of its histogram bucket in milliseconds
+	Series      []domain.StatsBucket
+	TopFailures []domain.FailureReasonCount
+}
+
+// GetStats retrieves the delivery statistics from the rollups
+func (s *Service) GetStats(ctx context.Context, filter domain.StatsFilter) (Stats, error) {
+	interval, ok := statsIntervals[filter.Interval]
+	if !ok {
+		return Stats{}, errors.Wrap(errors.ErrInvalidRequest, "invalid interval: "+filter.Interval)
+	}
+	if !filter.From.Before(filter.To) {
+		return Stats{}, errors.Wrap(errors.ErrInvalidRequest, "from must be before to")
+	}
+	if filter.To.Sub(filter.From)/interval > maxStatsBuckets {
+		return Stats{}, errors.Wrap(errors.ErrInvalidRequest, "time range has too many buckets for the interval")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	series, err := s.repo.GetStatsSeries(ctx, filter)
+	if err != nil {
+		return Stats{}, errors.Wrap(err, "get stats")
+	}
+
+	histogram, err := s.repo.GetLatencyHistogram(ctx, filter)
+	if err != nil {
+		return Stats{}, errors.Wrap(err, "get stats")
+	}
+
+	failures, err := s.repo.GetTopFailureReasons(ctx, filter, topFailureReasons)
+	if err != nil {
+		return Stats{}, errors.Wrap(err, "get stats")
+	}
+
+	statuses, err := s.repo.CountMessagesByStatus(ctx, filter)
+	if err != nil {
+		return Stats{}, errors.Wrap(err, "get stats")
+	}
+
+	stats := Stats{
+		Statuses:    statuses,
+		Series:      series,
+		TopFailures: failures,
+		Latency:     percentiles(histogram, latencyPercentiles),
+	}
+
+	var latencySum, latencyCount int64
+	for _, bucket := range series {
+		stats.Sent += bucket.Sent
+		stats.Failed += bucket.Failed
+		stats.Dead += bucket.Dead
+		latencySum += bucket.LatencySumMs
+		latencyCount += bucket.LatencyCount
+	}
+	if attempts := stats.Sent + stats.Failed; attempts > 0 {
+		stats.SuccessRate = float64(stats.Sent) / float64(attempts)
+	}
+	if latencyCount > 0 {
+		stats.AvgLatency = float64(latencySum) / float64(latencyCount)
+	}
+
+	return stats, nil
+}
+
+// percentiles estimates the percentiles from the histogram as the upper bound of the bucket they fall in
+func percentiles(histogram []domain.LatencyBucketCount, ps []int) map[int]int {
+	result := make(map[int]int, len(ps))
+
+	var total int64
+	for _, bucket := range histogram {
+		total += bucket.Count
+	}
+	if total == 0 {
+		return result
+	}
+
+	for _, p := range ps {
+		rank := (total*int64(p) + 99) / 100
+		var cumulative int64
+		for _, bucket := range histogram {
+			cumulative += bucket.Count
+			if cumulative >= rank {
+				result[p] = bucket.UpperBoundMs
+				break
+			}
+		}
+	}
+	return result
+}
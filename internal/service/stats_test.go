@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+func TestPercentiles(t *testing.T) {
+	ps := []int{50, 90, 95, 99}
+
+	tests := []struct {
+		name      string
+		histogram []domain.LatencyBucketCount
+		want      map[int]int
+	}{
+		{name: "no attempts", want: map[int]int{}},
+		{
+			name:      "single bucket",
+			histogram: []domain.LatencyBucketCount{{UpperBoundMs: 100, Count: 10}},
+			want:      map[int]int{50: 100, 90: 100, 95: 100, 99: 100},
+		},
+		{
+			name: "spread over buckets",
+			histogram: []domain.LatencyBucketCount{
+				{UpperBoundMs: 50, Count: 50},
+				{UpperBoundMs: 100, Count: 40},
+				{UpperBoundMs: 250, Count: 9},
+				{UpperBoundMs: 1000, Count: 1},
+			},
+			want: map[int]int{50: 50, 90: 100, 95: 250, 99: 250},
+		},
+		{
+			// The rank is rounded up, p50 of three attempts is the second one
+			name: "small sample",
+			histogram: []domain.LatencyBucketCount{
+				{UpperBoundMs: 50, Count: 1},
+				{UpperBoundMs: 100, Count: 1},
+				{UpperBoundMs: 250, Count: 1},
+			},
+			want: map[int]int{50: 100, 90: 250, 95: 250, 99: 250},
+		},
+		{
+			name: "empty buckets are skipped",
+			histogram: []domain.LatencyBucketCount{
+				{UpperBoundMs: 50, Count: 0},
+				{UpperBoundMs: 30000, Count: 4},
+			},
+			want: map[int]int{50: 30000, 90: 30000, 95: 30000, 99: 30000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentiles(tt.histogram, ps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("percentiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStatsTotals(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepository{
+		series: []domain.StatsBucket{
+			{Bucket: from, Sent: 6, Failed: 1, LatencySumMs: 700, LatencyCount: 7},
+			{Bucket: from.Add(time.Hour), Sent: 0, Failed: 3, Dead: 1, LatencySumMs: 900, LatencyCount: 3},
+		},
+		statuses: map[domain.MessageStatus]int64{domain.MessageStatusSent: 6, domain.MessageStatusFailed: 1},
+	}
+	s := &Service{repo: repo, cfg: &config.Config{}, httpTimeout: time.Second}
+
+	stats, err := s.GetStats(context.Background(), domain.StatsFilter{From: from, To: from.Add(2 * time.Hour), Interval: "hour"})
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+
+	// Attempts are summed over the series, a retried message counts every attempt
+	if stats.Sent != 6 || stats.Failed != 4 || stats.Dead != 1 {
+		t.Errorf("GetStats() attempts = %d sent, %d failed, %d dead, want 6, 4, 1", stats.Sent, stats.Failed, stats.Dead)
+	}
+	if stats.SuccessRate != 0.6 {
+		t.Errorf("GetStats() success rate = %v, want 0.6", stats.SuccessRate)
+	}
+	if stats.AvgLatency != 160 {
+		t.Errorf("GetStats() average latency = %v, want 160", stats.AvgLatency)
+	}
+	if !reflect.DeepEqual(stats.Statuses, repo.statuses) {
+		t.Errorf("GetStats() statuses = %v, want %v", stats.Statuses, repo.statuses)
+	}
+}
+
+func TestGetStatsRejectsInvalidRanges(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := &Service{repo: &fakeRepository{}, cfg: &config.Config{}, httpTimeout: time.Second}
+
+	filters := map[string]domain.StatsFilter{
+		"unknown interval": {From: from, To: from.Add(time.Hour), Interval: "week"},
+		"empty range":      {From: from, To: from, Interval: "minute"},
+		"reversed range":   {From: from, To: from.Add(-time.Hour), Interval: "minute"},
+		"too many buckets": {From: from, To: from.Add((maxStatsBuckets + 1) * time.Minute), Interval: "minute"},
+	}
+	for name, filter := range filters {
+		if _, err := s.GetStats(context.Background(), filter); !stderrors.Is(err, errors.ErrInvalidRequest) {
+			t.Errorf("%s: GetStats() error = %v, want %v", name, err, errors.ErrInvalidRequest)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"insider-challenge/internal/repository"
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+// Limits matching the sizes of the suppressions columns
+const (
+	maxSuppressionReasonLength = 255
+)
+
+// SuppressionList answers whether a recipient may be messaged, lookups are cached in redis
+type SuppressionList struct {
+	repo repository.Repository
+
+	// cacheTTL how long a suppressed lookup is cached, entries expiring sooner are cached until they expire
+	cacheTTL time.Duration
+
+	// negativeCacheTTL how long a lookup without a suppression is cached, kept short since a stale one lets a message through
+	negativeCacheTTL time.Duration
+
+	// requestTimeout default timeout for redis operations
+	requestTimeout time.Duration
+}
+
+// NewSuppressionList creates a new suppression list instance
+func NewSuppressionList(repo repository.Repository) *SuppressionList {
+	return &SuppressionList{
+		repo:             repo,
+		cacheTTL:         10 * time.Minute,
+		negativeCacheTTL: 5 * time.Second,
+		requestTimeout:   500 * time.Millisecond,
+	}
+}
+
+// Check returns the active suppression of the phone, nil when it may be messaged.
+// Redis failures fall back to the database, database failures are returned so the caller never sends unchecked.
+func (sl *SuppressionList) Check(ctx context.Context, phone string) (*domain.Suppression, error) {
+	cacheCtx, cancel := context.WithTimeout(ctx, sl.requestTimeout)
+	suppressed, found, err := config.GetSuppressionCache(cacheCtx, phone)
+	cancel()
+	if err != nil {
+		log.Printf("Suppression cache unavailable, checking the database: %v", err)
+	}
+	if found && !suppressed {
+		return nil, nil
+	}
+
+	// Positive hits are read from the database too, the entry carries the reason
+	suppression, err := sl.repo.GetSuppression(ctx, phone)
+	if err != nil && !stderrors.Is(err, errors.ErrSuppressionNotFound) {
+		return nil, err
+	}
+
+	if !found {
+		cacheCtx, cancel := context.WithTimeout(ctx, sl.requestTimeout)
+		defer cancel()
+
+		if err := config.FillSuppressionCache(cacheCtx, phone, suppression != nil, sl.lookupTTL(suppression)); err != nil {
+			log.Printf("Failed to cache suppression lookup: %v", err)
+		}
+	}
+	return suppression, nil
+}
+
+// invalidate overwrites the cached lookups after the entries were added or removed, when that fails the cached lookups
+// are deleted so the next check reads the database
+func (sl *SuppressionList) invalidate(ctx context.Context, entries []domain.Suppression, suppressed bool) {
+	ttls := make(map[string]time.Duration, len(entries))
+	for _, entry := range entries {
+		if suppressed {
+			ttls[entry.Phone] = sl.lookupTTL(&entry)
+		} else {
+			ttls[entry.Phone] = sl.lookupTTL(nil)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sl.requestTimeout)
+	defer cancel()
+
+	err := config.SetSuppressionCaches(ctx, ttls, suppressed)
+	if err == nil {
+		return
+	}
+	log.Printf("Failed to update suppression cache, deleting the cached lookups: %v", err)
+
+	phones := make([]string, 0, len(ttls))
+	for phone := range ttls {
+		phones = append(phones, phone)
+	}
+	if err := config.DeleteSuppressionCaches(ctx, phones); err != nil {
+		// A stale lookup without a suppression expires within the negative ttl, a stale suppressed one is checked against
+		// the database on every hit
+		log.Printf("Failed to delete suppression cache, stale lookups expire within %s: %v", sl.negativeCacheTTL, err)
+	}
+}
+
+// lookupTTL returns how long a lookup is cached, nil is a lookup without a suppression. The ttl of a suppressed lookup
+// is capped at the expiry of the entry.
+func (sl *SuppressionList) lookupTTL(suppression *domain.Suppression) time.Duration {
+	if suppression == nil {
+		return sl.negativeCacheTTL
+	}
+	if suppression.ExpiresAt != nil {
+		if remaining := time.Until(*suppression.ExpiresAt); remaining < sl.cacheTTL {
+			return max(remaining, time.Second)
+		}
+	}
+	return sl.cacheTTL
+}
+
+// SuppressionInput holds a suppression list entry to be added
+type SuppressionInput struct {
+	Phone     string
+	Reason    string
+	ExpiresAt *time.Time
+}
+
+// AddSuppression adds a phone number to the suppression list, an existing entry is replaced
+func (s *Service) AddSuppression(ctx context.Context, input SuppressionInput) (*domain.Suppression, error) {
+	entries, err := s.buildSuppressions([]SuppressionInput{input}, domain.SuppressionSourceAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveSuppressions(ctx, entries); err != nil {
+		return nil, err
+	}
+	return &entries[0], nil
+}
+
+// ImportSuppressions adds the entries to the suppression list in bulk and returns how many were stored
+func (s *Service) ImportSuppressions(ctx context.Context, inputs []SuppressionInput) (int, error) {
+	if len(inputs) == 0 {
+		return 0, errors.Wrap(errors.ErrInvalidRequest, "no entries to import")
+	}
+
+	entries, err := s.buildSuppressions(inputs, domain.SuppressionSourceImport)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.saveSuppressions(ctx, entries); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// GetSuppression retrieves the active suppression of a phone number
+func (s *Service) GetSuppression(ctx context.Context, phone string) (*domain.Suppression, error) {
+	phone, err := s.normalizePhone("phone", phone)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	suppression, err := s.repo.GetSuppression(ctx, phone)
+	if err != nil {
+		return nil, errors.Wrap(err, "get suppression")
+	}
+	return suppression, nil
+}
+
+// ListSuppressions retrieves a page of the active suppressions, hasMore reports a following page
+func (s *Service) ListSuppressions(ctx context.Context, page, pageSize int) ([]domain.Suppression, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	suppressions, err := s.repo.ListSuppressions(ctx, (page-1)*pageSize, pageSize+1)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "list suppressions")
+	}
+	if len(suppressions) > pageSize {
+		return suppressions[:pageSize], true, nil
+	}
+	return suppressions, false, nil
+}
+
+// DeleteSuppression removes a phone number from the suppression list
+func (s *Service) DeleteSuppression(ctx context.Context, phone string) error {
+	phone, err := s.normalizePhone("phone", phone)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if err := s.repo.DeleteSuppression(ctx, phone); err != nil {
+		return errors.Wrap(err, "delete suppression")
+	}
+
+	s.suppressions.invalidate(ctx, []domain.Suppression{{Phone: phone}}, false)
+	return nil
+}
+
+// saveSuppressions stores the entries and refreshes their cached lookups
+func (s *Service) saveSuppressions(ctx context.Context, entries []domain.Suppression) error {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	if err := s.repo.UpsertSuppressions(ctx, entries); err != nil {
+		return errors.Wrap(err, "save suppressions")
+	}
+
+	s.suppressions.invalidate(ctx, entries, true)
+	return nil
+}
+
+// buildSuppressions validates the inputs, a phone listed twice keeps its last entry
+func (s *Service) buildSuppressions(inputs []SuppressionInput, source domain.SuppressionSource) ([]domain.Suppression, error) {
+	now := time.Now()
+	positions := make(map[string]int, len(inputs))
+	entries := make([]domain.Suppression, 0, len(inputs))
+
+	for i, input := range inputs {
+		field := "phone"
+		if len(inputs) > 1 {
+			field = fmt.Sprintf("entries[%d].phone", i)
+		}
+		phone, err := s.normalizePhone(field, input.Phone)
+		if err != nil {
+			return nil, err
+		}
+		if utf8.RuneCountInString(input.Reason) > maxSuppressionReasonLength {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("entry %d: reason exceeds %d characters", i+1, maxSuppressionReasonLength))
+		}
+		if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("entry %d: expires_at must be in the future", i+1))
+		}
+
+		entry := domain.Suppression{
+			Phone:     phone,
+			Reason:    strings.TrimSpace(input.Reason),
+			Source:    source,
+			ExpiresAt: input.ExpiresAt,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if position, ok := positions[phone]; ok {
+			entries[position] = entry
+			continue
+		}
+		positions[phone] = len(entries)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
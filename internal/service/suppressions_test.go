@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "insider-challenge/pkg/domain"
+)
+
+func TestSuppressionLookupTTL(t *testing.T) {
+	sl := NewSuppressionList(&fakeRepository{})
+	at := func(d time.Duration) *time.Time {
+		expiresAt := time.Now().Add(d)
+		return &expiresAt
+	}
+
+	tests := []struct {
+		name        string
+		suppression *domain.Suppression
+		min, max    time.Duration
+	}{
+		{name: "not suppressed", suppression: nil, min: sl.negativeCacheTTL, max: sl.negativeCacheTTL},
+		{name: "without expiry", suppression: &domain.Suppression{}, min: sl.cacheTTL, max: sl.cacheTTL},
+		{name: "expires after the cache ttl", suppression: &domain.Suppression{ExpiresAt: at(time.Hour)}, min: sl.cacheTTL, max: sl.cacheTTL},
+		{name: "expires sooner", suppression: &domain.Suppression{ExpiresAt: at(time.Minute)}, min: 59 * time.Second, max: time.Minute},
+		{name: "expired meanwhile", suppression: &domain.Suppression{ExpiresAt: at(-time.Minute)}, min: time.Second, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sl.lookupTTL(tt.suppression); got < tt.min || got > tt.max {
+				t.Errorf("lookupTTL() = %s, want between %s and %s", got, tt.min, tt.max)
+			}
+		})
+	}
+
+	// A stale negative lookup lets messages to a suppressed number through, it must never live long
+	if sl.negativeCacheTTL > 10*time.Second {
+		t.Errorf("negative lookups are cached for %s", sl.negativeCacheTTL)
+	}
+}
+
+func TestSuppressionCheckWithoutRedis(t *testing.T) {
+	withUnavailableRedis(t)
+
+	suppressed := &domain.Suppression{Phone: "+905071773757", Reason: "unsubscribed"}
+	sl := NewSuppressionList(&fakeRepository{suppressions: map[string]*domain.Suppression{suppressed.Phone: suppressed}})
+
+	got, err := sl.Check(context.Background(), suppressed.Phone)
+	if err != nil || got != suppressed {
+		t.Errorf("Check(suppressed) = %v, %v, want the suppression from the database", got, err)
+	}
+
+	got, err = sl.Check(context.Background(), "+905071773758")
+	if err != nil || got != nil {
+		t.Errorf("Check(not suppressed) = %v, %v, want no suppression", got, err)
+	}
+
+	// Neither the cache update nor deleting the cached lookups can reach redis, the write itself is not failed
+	sl.invalidate(context.Background(), []domain.Suppression{*suppressed}, true)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+var (
+	templateIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	localePattern     = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// TemplateInput holds a new version of a template
+type TemplateInput struct {
+	ID          string
+	Description string
+	Variants    map[string]string
+}
+
+// RenderInput selects a template variant and the values of its placeholders
+type RenderInput struct {
+	TemplateID string
+	Version    int // Latest when zero
+	Locale     string
+	Variables  map[string]string
+}
+
+// RenderedTemplate is the content rendered from a template variant
+type RenderedTemplate struct {
+	Content    string
+	TemplateID string
+	Version    int
+	Locale     string
+}
+
+// CreateTemplate validates the variants and stores them as the next version of the template
+func (s *Service) CreateTemplate(ctx context.Context, input TemplateInput) (*domain.Template, error) {
+	if !templateIDPattern.MatchString(input.ID) {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "id must be 1-64 lowercase letters, digits, _ or -")
+	}
+	if utf8.RuneCountInString(input.Description) > 255 {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "description exceeds 255 characters")
+	}
+	if len(input.Variants) == 0 {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "at least one locale variant is required")
+	}
+	for locale, body := range input.Variants {
+		if !localePattern.MatchString(locale) {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, "invalid locale: "+locale)
+		}
+		if strings.TrimSpace(body) == "" {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("%s variant is empty", locale))
+		}
+		if _, err := parseTemplate(body); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("%s variant: %v", locale, err))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	tmpl := &domain.Template{
+		ID:          input.ID,
+		Description: input.Description,
+		Variants:    input.Variants,
+	}
+	if err := s.repo.CreateTemplateVersion(ctx, tmpl); err != nil {
+		return nil, errors.Wrap(err, "create template")
+	}
+	return tmpl, nil
+}
+
+// GetTemplate retrieves a version of a template, version 0 is the latest
+func (s *Service) GetTemplate(ctx context.Context, id string, version int) (*domain.Template, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	tmpl, err := s.repo.GetTemplate(ctx, id, version)
+	if err != nil {
+		return nil, errors.Wrap(err, "get template")
+	}
+	return tmpl, nil
+}
+
+// ListTemplates retrieves the latest version of every template
+func (s *Service) ListTemplates(ctx context.Context) ([]domain.Template, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.httpTimeout)
+	defer cancel()
+
+	templates, err := s.repo.ListTemplates(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list templates")
+	}
+	return templates, nil
+}
+
+// RenderTemplate renders a template variant, every placeholder must have a value
+func (s *Service) RenderTemplate(ctx context.Context, input RenderInput) (*RenderedTemplate, error) {
+	if input.Locale == "" {
+		input.Locale = s.cfg.DefaultLocale
+	}
+
+	tmpl, err := s.GetTemplate(ctx, input.TemplateID, input.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	body, ok := tmpl.Variants[input.Locale]
+	if !ok {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, fmt.Sprintf("template %s version %d has no %s variant", tmpl.ID, tmpl.Version, input.Locale))
+	}
+
+	parsed, err := parseTemplate(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse template")
+	}
+
+	var content strings.Builder
+	if err := parsed.Execute(&content, input.Variables); err != nil {
+		return nil, errors.Wrap(errors.ErrInvalidRequest, "render template: "+err.Error())
+	}
+
+	return &RenderedTemplate{
+		Content:    content.String(),
+		TemplateID: tmpl.ID,
+		Version:    tmpl.Version,
+		Locale:     input.Locale,
+	}, nil
+}
+
+// parseTemplate parses a variant body, rendering fails on placeholders without a value
+func parseTemplate(body string) (*template.Template, error) {
+	return template.New("").Option("missingkey=error").Parse(body)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"insider-challenge/pkg/config"
+	domain "insider-challenge/pkg/domain"
+	"insider-challenge/pkg/errors"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	repo := &fakeRepository{templates: map[string]*domain.Template{
+		"otp": {ID: "otp", Version: 2, Variants: map[string]string{
+			"tr": "Kodunuz {{.code}}",
+			"en": "Your code is {{.code}}, valid for {{.minutes}} minutes",
+		}},
+	}}
+	s := &Service{repo: repo, cfg: &config.Config{DefaultLocale: "tr"}, httpTimeout: time.Second}
+
+	tests := []struct {
+		name    string
+		input   RenderInput
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "default locale",
+			input: RenderInput{TemplateID: "otp", Variables: map[string]string{"code": "1234"}},
+			want:  "Kodunuz 1234",
+		},
+		{
+			name:  "requested locale and version",
+			input: RenderInput{TemplateID: "otp", Version: 2, Locale: "en", Variables: map[string]string{"code": "1234", "minutes": "5"}},
+			want:  "Your code is 1234, valid for 5 minutes",
+		},
+		{
+			name:  "unused variables are ignored",
+			input: RenderInput{TemplateID: "otp", Variables: map[string]string{"code": "1234", "name": "Ada"}},
+			want:  "Kodunuz 1234",
+		},
+		{
+			name:    "missing variable",
+			input:   RenderInput{TemplateID: "otp", Locale: "en", Variables: map[string]string{"code": "1234"}},
+			wantErr: errors.ErrInvalidRequest,
+		},
+		{
+			name:    "missing locale",
+			input:   RenderInput{TemplateID: "otp", Locale: "de", Variables: map[string]string{"code": "1234"}},
+			wantErr: errors.ErrInvalidRequest,
+		},
+		{
+			name:    "unknown version",
+			input:   RenderInput{TemplateID: "otp", Version: 1},
+			wantErr: errors.ErrTemplateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := s.RenderTemplate(context.Background(), tt.input)
+			if tt.wantErr != nil {
+				if !stderrors.Is(err, tt.wantErr) {
+					t.Errorf("RenderTemplate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderTemplate() error = %v", err)
+			}
+			if rendered.Content != tt.want || rendered.Version != 2 {
+				t.Errorf("RenderTemplate() = %q version %d, want %q version 2", rendered.Content, rendered.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateTemplateValidation(t *testing.T) {
+	// Invalid templates are rejected before the repository is called, the embedded nil interface would panic otherwise
+	s := &Service{repo: &fakeRepository{}, cfg: &config.Config{}, httpTimeout: time.Second}
+
+	inputs := map[string]TemplateInput{
+		"uppercase id":    {ID: "OTP", Variants: map[string]string{"tr": "Kod {{.code}}"}},
+		"no variants":     {ID: "otp"},
+		"invalid locale":  {ID: "otp", Variants: map[string]string{"tr-TR": "Kod {{.code}}"}},
+		"empty variant":   {ID: "otp", Variants: map[string]string{"tr": "  "}},
+		"unclosed action": {ID: "otp", Variants: map[string]string{"tr": "Kod {{.code"}},
+	}
+	for name, input := range inputs {
+		if _, err := s.CreateTemplate(context.Background(), input); !stderrors.Is(err, errors.ErrInvalidRequest) {
+			t.Errorf("%s: CreateTemplate() error = %v, want %v", name, err, errors.ErrInvalidRequest)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -20,6 +22,48 @@ type Config struct {
 	WebhookAuthKey  string
 	DefaultPageSize int
 	MaxPageSize     int
+	SenderAutoStart bool
+	MaxSendAttempts int
+	ProviderName    string
+
+	InstanceID            string
+	LeaderElectionEnabled bool
+	LeaderLeaseTTL        time.Duration
+
+	NotifyEnabled  bool
+	NotifyDebounce time.Duration
+
+	EventsReplaySize int
+
+	CallbackSigningSecret string
+	CallbackTimeout       time.Duration
+	CallbackMaxAttempts   int
+	CallbackWorkers       int
+
+	ProviderTokens map[string]string
+	OptOutKeywords []string
+
+	DefaultLocale string
+	DefaultRegion string
+
+	SegmentPolicy string
+	Transliterate bool
+
+	AllowedCountries  []string
+	BlockedCountries  []string
+	CountryRateLimits map[string]string
+	CountryQuietHours map[string]string
+	CountryTimezones  map[string]string
+
+	SendingWindow   string
+	SendingTimezone string
+
+	DefaultPriority string
+	LaneWeights     map[string]string
+
+	IdempotencyTTL time.Duration
+
+	DedupWindow time.Duration
 }
 
 // Load loads configuration from env
@@ -39,6 +83,48 @@ func Load() (*Config, error) {
 		WebhookAuthKey:  getEnv("WEBHOOK_AUTH_KEY", ""),
 		DefaultPageSize: getEnvAsInt("DEFAULT_PAGE_SIZE", 10),
 		MaxPageSize:     getEnvAsInt("MAX_PAGE_SIZE", 100),
+		SenderAutoStart: getEnvAsBool("SENDER_AUTO_START", true),
+		MaxSendAttempts: getEnvAsInt("MAX_SEND_ATTEMPTS", 5),
+		ProviderName:    getEnv("PROVIDER_NAME", "webhook"),
+
+		InstanceID:            getEnv("INSTANCE_ID", defaultInstanceID()),
+		LeaderElectionEnabled: getEnvAsBool("LEADER_ELECTION_ENABLED", false),
+		LeaderLeaseTTL:        getEnvAsMinDuration("LEADER_LEASE_TTL", 15*time.Second, time.Second),
+
+		NotifyEnabled:  getEnvAsBool("NOTIFY_ENABLED", false),
+		NotifyDebounce: getEnvAsMinDuration("NOTIFY_DEBOUNCE", 1*time.Second, 0),
+
+		EventsReplaySize: getEnvAsInt("EVENTS_REPLAY_SIZE", 1000),
+
+		CallbackSigningSecret: getEnv("CALLBACK_SIGNING_SECRET", ""),
+		CallbackTimeout:       getEnvAsMinDuration("CALLBACK_TIMEOUT", 5*time.Second, time.Second),
+		CallbackMaxAttempts:   getEnvAsInt("CALLBACK_MAX_ATTEMPTS", 8),
+		CallbackWorkers:       getEnvAsInt("CALLBACK_WORKERS", 4),
+
+		ProviderTokens: getEnvAsMap("PROVIDER_TOKENS"),
+		OptOutKeywords: getEnvAsSlice("OPT_OUT_KEYWORDS", []string{"STOP", "DUR", "IPTAL"}),
+
+		DefaultLocale: getEnv("DEFAULT_LOCALE", "tr"),
+		DefaultRegion: getEnv("DEFAULT_REGION", "TR"),
+
+		SegmentPolicy: getEnv("SEGMENT_POLICY", "allow_multipart"),
+		Transliterate: getEnvAsBool("TRANSLITERATE", false),
+
+		AllowedCountries:  getEnvAsSlice("ALLOWED_COUNTRIES", nil),
+		BlockedCountries:  getEnvAsSlice("BLOCKED_COUNTRIES", nil),
+		CountryRateLimits: getEnvAsMap("COUNTRY_RATE_LIMITS"),
+		CountryQuietHours: getEnvAsMap("COUNTRY_QUIET_HOURS"),
+		CountryTimezones:  getEnvAsMap("COUNTRY_TIMEZONES"),
+
+		SendingWindow:   getEnv("SENDING_WINDOW", ""),
+		SendingTimezone: getEnv("SENDING_TIMEZONE", "Europe/Istanbul"),
+
+		DefaultPriority: getEnv("DEFAULT_PRIORITY", "transactional"),
+		LaneWeights:     getEnvAsMap("LANE_WEIGHTS"),
+
+		IdempotencyTTL: getEnvAsDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		DedupWindow: getEnvAsDuration("DEDUP_WINDOW", 0),
 	}, nil
 }
 
@@ -59,3 +145,69 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool retrieves an environment variable as a boolean or return default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration retrieves an environment variable as a duration or return default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsMinDuration retrieves an environment variable as a duration of at least minValue, smaller values are logged and
+// replaced by the default since they feed tickers and timeouts that would panic or expire immediately
+func getEnvAsMinDuration(key string, defaultValue, minValue time.Duration) time.Duration {
+	value := getEnvAsDuration(key, defaultValue)
+	if value < minValue {
+		log.Printf("%s must be at least %s, using %s", key, minValue, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvAsSlice retrieves an environment variable of comma separated values or return default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvAsMap retrieves an environment variable of comma separated key:value pairs, malformed pairs are skipped
+func getEnvAsMap(key string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || k == "" || v == "" {
+			continue
+		}
+		values[k] = v
+	}
+	return values
+}
+
+// defaultInstanceID identifies the replica by its hostname, which is the container id under docker
+func defaultInstanceID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "unknown"
+}
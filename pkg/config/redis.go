@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"time"
 
@@ -62,3 +63,226 @@ func GetMessageCache(ctx context.Context, messageID string) (*MessageCache, erro
 
 	return &cache, nil
 }
+
+// GetMessageCaches retrieves the cache of several messages in a single round trip, missing messages are left out
+func GetMessageCaches(ctx context.Context, messageIDs []string) (map[string]*MessageCache, error) {
+	caches := make(map[string]*MessageCache, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return caches, nil
+	}
+
+	keys := make([]string, len(messageIDs))
+	for i, messageID := range messageIDs {
+		keys[i] = "message:" + messageID
+	}
+
+	values, err := RedisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var cache MessageCache
+		if err := json.Unmarshal([]byte(data), &cache); err != nil {
+			continue
+		}
+		caches[messageIDs[i]] = &cache
+	}
+
+	return caches, nil
+}
+
+// senderStateChannel broadcasts desired sender state changes, the state itself is persisted in postgres
+const senderStateChannel = "sender:state:changes"
+
+// PublishSenderState broadcasts the desired sender state to all replicas
+func PublishSenderState(ctx context.Context, state string) error {
+	return RedisClient.Publish(ctx, senderStateChannel, state).Err()
+}
+
+// SubscribeSenderState subscribes to sender state changes published by any replica
+func SubscribeSenderState(ctx context.Context) *redis.PubSub {
+	return RedisClient.Subscribe(ctx, senderStateChannel)
+}
+
+// renewLeaseScript extends the lease only when it is still held by the owner
+var renewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaseScript deletes the lease only when it is still held by the owner
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// AcquireLease tries to take the lease for the owner, returns false if it is held by someone else
+func AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return RedisClient.SetNX(ctx, key, owner, ttl).Result()
+}
+
+// RenewLease extends the lease, returns false if the owner no longer holds it
+func RenewLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLeaseScript.Run(ctx, RedisClient, []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+// ReleaseLease gives up the lease if the owner still holds it
+func ReleaseLease(ctx context.Context, key, owner string) error {
+	return releaseLeaseScript.Run(ctx, RedisClient, []string{key}, owner).Err()
+}
+
+// GetLeaseOwner retrieves the current lease owner, returns redis.Nil if nobody holds it
+func GetLeaseOwner(ctx context.Context, key string) (string, error) {
+	return RedisClient.Get(ctx, key).Result()
+}
+
+const (
+	eventsStreamKey = "message:events"
+	eventsChannel   = "message:events:live"
+)
+
+// publishEventScript appends the event to the capped replay stream and broadcasts it with its stream id
+var publishEventScript = redis.NewScript(`
+local id = redis.call("XADD", KEYS[1], "MAXLEN", "~", ARGV[1], "*", "data", ARGV[2])
+redis.call("PUBLISH", ARGV[3], id .. " " .. ARGV[2])
+return id
+`)
+
+// StreamEvent is an event read back from the replay stream
+type StreamEvent struct {
+	ID   string
+	Data string
+}
+
+// PublishEvent stores the event in the replay buffer of maxLen entries and broadcasts it, returns its id
+func PublishEvent(ctx context.Context, data []byte, maxLen int) (string, error) {
+	return publishEventScript.Run(ctx, RedisClient, []string{eventsStreamKey}, maxLen, data, eventsChannel).Text()
+}
+
+// SubscribeEvents subscribes to the live events, payloads are "<id> <data>"
+func SubscribeEvents(ctx context.Context) *redis.PubSub {
+	return RedisClient.Subscribe(ctx, eventsChannel)
+}
+
+// GetEventsAfter retrieves up to count buffered events published after the given id
+func GetEventsAfter(ctx context.Context, lastID string, count int64) ([]StreamEvent, error) {
+	entries, err := RedisClient.XRangeN(ctx, eventsStreamKey, "("+lastID, "+", count).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]StreamEvent, 0, len(entries))
+	for _, entry := range entries {
+		data, _ := entry.Values["data"].(string)
+		events = append(events, StreamEvent{ID: entry.ID, Data: data})
+	}
+	return events, nil
+}
+
+// suppressionKeyPrefix prefix of the cached suppression list lookups
+const suppressionKeyPrefix = "suppression:"
+
+// GetSuppressionCache retrieves a cached suppression lookup, found is false on a cache miss
+func GetSuppressionCache(ctx context.Context, phone string) (suppressed bool, found bool, err error) {
+	value, err := RedisClient.Get(ctx, suppressionKeyPrefix+phone).Result()
+	if err == redis.Nil {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return value == "1", true, nil
+}
+
+// FillSuppressionCache caches a lookup read from the database unless a write already replaced it
+func FillSuppressionCache(ctx context.Context, phone string, suppressed bool, ttl time.Duration) error {
+	return RedisClient.SetNX(ctx, suppressionKeyPrefix+phone, suppressionCacheValue(suppressed), ttl).Err()
+}
+
+// SetSuppressionCaches overwrites the cached lookups of the phones, keyed with their ttl, after the suppression list changed
+func SetSuppressionCaches(ctx context.Context, ttls map[string]time.Duration, suppressed bool) error {
+	_, err := RedisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for phone, ttl := range ttls {
+			pipe.Set(ctx, suppressionKeyPrefix+phone, suppressionCacheValue(suppressed), ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteSuppressionCaches removes the cached lookups of the phones
+func DeleteSuppressionCaches(ctx context.Context, phones []string) error {
+	keys := make([]string, len(phones))
+	for i, phone := range phones {
+		keys[i] = suppressionKeyPrefix + phone
+	}
+	return RedisClient.Del(ctx, keys...).Err()
+}
+
+// suppressionCacheValue encodes a lookup result
+func suppressionCacheValue(suppressed bool) string {
+	if suppressed {
+		return "1"
+	}
+	return "0"
+}
+
+// countryRateKeyPrefix prefix of the per country send counters
+const countryRateKeyPrefix = "country_rate:"
+
+// IncrementCountryRate counts a send to the country in the window starting at windowStart and returns the window total
+func IncrementCountryRate(ctx context.Context, country string, windowStart time.Time, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("%s%s:%d", countryRateKeyPrefix, country, windowStart.Unix())
+
+	pipe := RedisClient.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 2*window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return count.Val(), nil
+}
+
+// dedupKeyPrefix prefix of the content hashes seen within the dedup window
+const dedupKeyPrefix = "dedup:"
+
+// ClaimDedupKey records the content hash for the window, false when it was already recorded
+func ClaimDedupKey(ctx context.Context, contentHash string, window time.Duration) (bool, error) {
+	return RedisClient.SetNX(ctx, dedupKeyPrefix+contentHash, "1", window).Result()
+}
+
+// ReleaseDedupKey forgets the content hash, used when the message that claimed it was not stored
+func ReleaseDedupKey(ctx context.Context, contentHash string) error {
+	return RedisClient.Del(ctx, dedupKeyPrefix+contentHash).Err()
+}
+
+// dedupSendKeyPrefix prefix of the content hashes sent within the dedup window
+const dedupSendKeyPrefix = "dedup:send:"
+
+// ClaimDedupSend records the message as the one sending the content hash for the window. It returns the id of the message
+// that claimed the hash before, empty when the claim is new.
+func ClaimDedupSend(ctx context.Context, contentHash, messageID string, window time.Duration) (string, error) {
+	owner, err := RedisClient.SetArgs(ctx, dedupSendKeyPrefix+contentHash, messageID, redis.SetArgs{
+		Mode: "NX",
+		TTL:  window,
+		Get:  true,
+	}).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return owner, err
+}
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// BlackoutDateLayout is the format of blackout dates
+const BlackoutDateLayout = "2006-01-02"
+
+// BlackoutDate is a calendar day in the sending time zone on which messages are not sent, e.g. a public holiday
+type BlackoutDate struct {
+	Date        string    `gorm:"primaryKey;size:10" json:"date" example:"2026-10-29"` // YYYY-MM-DD
+	Description string    `gorm:"size:255" json:"description,omitempty" example:"Republic Day"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
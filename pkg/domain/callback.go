@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CallbackStatus is the delivery state of a status callback
+type CallbackStatus string
+
+const (
+	CallbackStatusPending   CallbackStatus = "pending"
+	CallbackStatusDelivered CallbackStatus = "delivered"
+	CallbackStatusFailed    CallbackStatus = "failed" // Gave up after the maximum number of attempts
+)
+
+// CallbackDelivery is a queued status callback to a client supplied url
+type CallbackDelivery struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	MessageID      uuid.UUID          `gorm:"type:uuid;not null;index" json:"message_id"`
+	URL            string             `gorm:"size:2048;not null" json:"url"`
+	Event          LifecycleEventType `gorm:"type:varchar(20);not null" json:"event"`
+	Payload        string             `gorm:"type:text;not null" json:"payload"`
+	Status         CallbackStatus     `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+	Attempts       int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time          `gorm:"not null" json:"next_attempt_at"`
+	LastStatusCode int                `json:"last_status_code,omitempty"`
+	LastError      string             `gorm:"size:255" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time         `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	Log            []CallbackAttempt  `gorm:"foreignKey:DeliveryID" json:"log,omitempty"`
+}
+
+// CallbackAttempt is a single entry of the callback delivery log
+type CallbackAttempt struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	DeliveryID  uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	Attempt     int       `gorm:"not null" json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `gorm:"size:255" json:"error,omitempty"`
+	LatencyMs   int64     `json:"latency_ms"`
+	AttemptedAt time.Time `gorm:"not null" json:"attempted_at"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LifecycleEventType is the kind of a message lifecycle event
+type LifecycleEventType string
+
+const (
+	LifecycleEventCreated    LifecycleEventType = "created"
+	LifecycleEventSent       LifecycleEventType = "sent"
+	LifecycleEventFailed     LifecycleEventType = "failed"     // An attempt failed, the message will be retried
+	LifecycleEventDead       LifecycleEventType = "dead"       // The last attempt failed, the message will not be retried
+	LifecycleEventSuppressed LifecycleEventType = "suppressed" // The recipient is on the suppression list, the message will not be sent
+	LifecycleEventBlocked    LifecycleEventType = "blocked"    // The destination country is not allowed, the message will not be sent
+	LifecycleEventDeferred   LifecycleEventType = "deferred"   // Quiet hours or the country rate limit postponed the message
+	LifecycleEventDuplicate  LifecycleEventType = "duplicate"  // The same content was sent to the recipient within the dedup window
+)
+
+// LifecycleEvent is broadcast to every replica whenever a message changes state
+type LifecycleEvent struct {
+	ID         string             `json:"id,omitempty"` // Position in the replay buffer, assigned on publish
+	Type       LifecycleEventType `json:"type"`
+	MessageID  uuid.UUID          `json:"message_id"`
+	To         string             `json:"to"`
+	Status     MessageStatus      `json:"status"`
+	Provider   string             `json:"provider,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
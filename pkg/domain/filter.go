@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sortable message fields, anything else is rejected before reaching the database
+const (
+	SortBySentAt    = "sent_at"
+	SortByCreatedAt = "created_at"
+	SortByTo        = "to"
+)
+
+// MessageFilter narrows down and orders message listings, zero values mean no filter
+type MessageFilter struct {
+	To              string // Exact recipient match
+	ToPrefix        string // Recipient prefix match, e.g. a country calling code
+	SentAfter       *time.Time
+	SentBefore      *time.Time
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	Provider        string
+	ContentContains string // Case insensitive content substring
+	SortBy          string
+	SortAsc         bool
+}
+
+// IsValidSortField reports whether the field can be used to sort message listings
+func IsValidSortField(field string) bool {
+	switch field {
+	case SortBySentAt, SortByCreatedAt, SortByTo:
+		return true
+	}
+	return false
+}
+
+// MessageCursor is a keyset position in a sent_at ordered listing
+type MessageCursor struct {
+	SentAt   time.Time
+	ID       uuid.UUID
+	Backward bool // Page towards the start of the listing instead of the end
+	SortAsc  bool // Order of the listing the cursor was issued for
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MessageEventKind is the kind of an entry in the message history
+type MessageEventKind string
+
+const (
+	MessageEventTransition MessageEventKind = "transition" // Status change made by the service
+	MessageEventAttempt    MessageEventKind = "attempt"    // Webhook request, successful or not
+	MessageEventAction     MessageEventKind = "action"     // Operator action through the api
+	MessageEventReceipt    MessageEventKind = "receipt"    // Delivery receipt reported by the provider
+	MessageEventDeferral   MessageEventKind = "deferral"   // Sending postponed by the country policy
+)
+
+// Operator actions recorded in the message history
+const (
+	MessageActionCancel = "cancel"
+	MessageActionSend   = "send"
+	MessageActionResend = "resend"
+)
+
+// MaxResponseBodyLength is the stored length of webhook response bodies in the message history
+const MaxResponseBodyLength = 1024
+
+// MessageEvent is an append only entry of the message history, rows are never updated
+type MessageEvent struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	MessageID    uuid.UUID        `gorm:"type:uuid;not null;index" json:"message_id"`
+	Kind         MessageEventKind `gorm:"type:varchar(20);not null" json:"kind"`
+	Action       string           `gorm:"size:20" json:"action,omitempty"`
+	FromStatus   MessageStatus    `gorm:"type:varchar(20)" json:"from_status,omitempty"`
+	ToStatus     MessageStatus    `gorm:"type:varchar(20)" json:"to_status,omitempty"`
+	Provider     string           `gorm:"size:50" json:"provider,omitempty"`
+	StatusCode   int              `json:"status_code,omitempty"`
+	LatencyMs    int64            `json:"latency_ms,omitempty"`
+	ResponseBody string           `gorm:"size:1024" json:"response_body,omitempty"`
+	Error        string           `gorm:"size:255" json:"error,omitempty"`
+	Detail       string           `gorm:"size:255" json:"detail,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey remembers the message created for a client supplied key so retried requests return it instead of a duplicate
+type IdempotencyKey struct {
+	ClientID    string    `gorm:"primaryKey;size:64" json:"client_id"` // X-Client-ID of the caller
+	Key         string    `gorm:"primaryKey;size:255" json:"key"`
+	RequestHash string    `gorm:"size:64;not null" json:"-"` // Fingerprint of the request, a key reused with another request is rejected
+	MessageID   uuid.UUID `gorm:"type:uuid;not null" json:"message_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuppressionSourceInbound entries added by an opt-out keyword in a reply
+const SuppressionSourceInbound SuppressionSource = "inbound"
+
+// InboundMessage is a reply received from a recipient through a provider
+type InboundMessage struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Provider          string     `gorm:"size:50;not null" json:"provider"`
+	ProviderMessageID string     `gorm:"size:100" json:"provider_message_id,omitempty"`
+	From              string     `gorm:"size:32;not null;index:idx_inbound_messages_from,priority:1" json:"from"`
+	To                string     `gorm:"size:32" json:"to,omitempty"`
+	Content           string     `gorm:"type:text;not null" json:"content"`
+	MessageID         *uuid.UUID `gorm:"type:uuid;index" json:"message_id,omitempty"` // Most recent outbound message to the sender of the reply
+	OptOutKeyword     string     `gorm:"size:20" json:"opt_out_keyword,omitempty"`    // Set when the reply opted the sender out
+	ReceivedAt        time.Time  `gorm:"not null;index:idx_inbound_messages_from,priority:2,sort:desc" json:"received_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -7,14 +7,103 @@ import (
 	"gorm.io/gorm"
 )
 
+// MessageStatus is the lifecycle state of a message
+type MessageStatus string
+
+const (
+	MessageStatusPending    MessageStatus = "pending"
+	MessageStatusSent       MessageStatus = "sent"
+	MessageStatusFailed     MessageStatus = "failed"     // Gave up after the maximum number of attempts
+	MessageStatusCancelled  MessageStatus = "cancelled"  // Cancelled by an operator before sending
+	MessageStatusSuppressed MessageStatus = "suppressed" // Recipient is on the suppression list, never sent
+	MessageStatusBlocked    MessageStatus = "blocked"    // Destination country is not allowed by the country policy, never sent
+	MessageStatusDuplicate  MessageStatus = "duplicate"  // Same content to the same recipient within the dedup window, never sent
+)
+
+// MessageStatuses lists every message status
+var MessageStatuses = []MessageStatus{
+	MessageStatusPending,
+	MessageStatusSent,
+	MessageStatusFailed,
+	MessageStatusCancelled,
+	MessageStatusSuppressed,
+	MessageStatusBlocked,
+	MessageStatusDuplicate,
+}
+
+// Priority is the sending lane of a message
+type Priority string
+
+const (
+	PriorityCritical      Priority = "critical" // Always fills the batch first
+	PriorityTransactional Priority = "transactional"
+	PriorityBulk          Priority = "bulk" // Marketing, shares the batch with transactional by weight
+)
+
+// Priorities lists the lanes from the highest priority
+var Priorities = []Priority{PriorityCritical, PriorityTransactional, PriorityBulk}
+
+// IsValid checks if the priority is one of the known lanes
+func (p Priority) IsValid() bool {
+	switch p {
+	case PriorityCritical, PriorityTransactional, PriorityBulk:
+		return true
+	}
+	return false
+}
+
+// MaxContentLength is the character limit of the message content
+const MaxContentLength = 150
+
+// SegmentPolicy decides what happens to content that does not fit a single segment
+type SegmentPolicy string
+
+const (
+	SegmentPolicyAllowMultipart SegmentPolicy = "allow_multipart"
+	SegmentPolicyReject         SegmentPolicy = "reject"
+	SegmentPolicyTransliterate  SegmentPolicy = "transliterate" // Replace characters outside GSM-7, multipart is allowed if it still does not fit
+)
+
+// IsValid checks if the segment policy is one of the known values
+func (p SegmentPolicy) IsValid() bool {
+	switch p {
+	case SegmentPolicyAllowMultipart, SegmentPolicyReject, SegmentPolicyTransliterate:
+		return true
+	}
+	return false
+}
+
 // Message structure
 type Message struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	To        string         `gorm:"not null" json:"to"`
-	Content   string         `gorm:"not null;size:150" json:"content"` // Maximum 150 character (character limit is required for message content)
-	IsSent    bool           `gorm:"default:false;index" json:"is_sent"`
-	SentAt    *time.Time     `gorm:"index" json:"sent_at"`
-	CreatedAt time.Time      `gorm:"index" json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	To                string         `gorm:"not null" json:"to"`
+	Country           string         `gorm:"size:2;index" json:"country,omitempty"` // Destination region derived from the calling code of to
+	ContentHash       string         `gorm:"size:64;index" json:"-"`                // sha256 of to and content, empty for resends which are never deduplicated
+	DuplicateOf       *uuid.UUID     `gorm:"type:uuid" json:"duplicate_of,omitempty"`
+	Content           string         `gorm:"not null;size:150" json:"content"`            // Maximum 150 character (character limit is required for message content)
+	Encoding          string         `gorm:"size:10" json:"encoding,omitempty"`           // gsm7 or ucs2
+	Segments          int            `json:"segments,omitempty"`                          // Parts the carrier splits the content into
+	OriginalContent   string         `gorm:"type:text" json:"original_content,omitempty"` // Content before transliteration, kept for audit
+	Transliterated    bool           `gorm:"not null;default:false" json:"transliterated"`
+	Status            MessageStatus  `gorm:"type:varchar(20);not null;default:pending;index" json:"status"`
+	Priority          Priority       `gorm:"type:varchar(20);not null;default:transactional" json:"priority"`
+	IsSent            bool           `gorm:"default:false;index" json:"is_sent"`
+	SentAt            *time.Time     `gorm:"index" json:"sent_at"`
+	Attempts          int            `gorm:"not null;default:0" json:"attempts"`
+	NotBefore         *time.Time     `json:"not_before,omitempty"`                             // Deferred by the sending policy, the sender skips the message until then
+	AnnouncedAt       *time.Time     `json:"-"`                                                // When the created event was published, the sender announces rows inserted outside the service
+	BypassQuietHours  bool           `gorm:"not null;default:false" json:"bypass_quiet_hours"` // Transactional messages such as OTPs ignore sending windows, blackout dates and quiet hours
+	LastError         string         `gorm:"size:255" json:"last_error,omitempty"`
+	Provider          string         `gorm:"size:50" json:"provider,omitempty"`
+	ProviderMessageID string         `gorm:"size:100;index" json:"provider_message_id,omitempty"`
+	CallbackURL       string         `gorm:"size:2048" json:"callback_url,omitempty"`           // Receives signed status events when the message is sent or fails permanently
+	DeliveryStatus    DeliveryStatus `gorm:"type:varchar(20)" json:"delivery_status,omitempty"` // Reported by the provider receipt, sent only means the webhook accepted it
+	DeliveredAt       *time.Time     `json:"delivered_at,omitempty"`
+	LastReceiptAt     *time.Time     `json:"-"`                                    // Time of the receipt delivery_status came from, older receipts never overwrite it
+	TemplateID        string         `gorm:"size:64" json:"template_id,omitempty"` // Template the content was rendered from
+	TemplateVersion   int            `json:"template_version,omitempty"`
+	Locale            string         `gorm:"size:10" json:"locale,omitempty"`
+	CreatedAt         time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 }
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeliveryStatus is the handset delivery state reported by the provider
+type DeliveryStatus string
+
+const (
+	DeliveryStatusEnroute     DeliveryStatus = "enroute" // Intermediate, the carrier is still trying to reach the handset
+	DeliveryStatusDelivered   DeliveryStatus = "delivered"
+	DeliveryStatusUndelivered DeliveryStatus = "undelivered"
+	DeliveryStatusExpired     DeliveryStatus = "expired" // Validity period passed before the handset was reachable
+)
+
+// FinalDeliveryStatuses lists the statuses a message never leaves once reported
+var FinalDeliveryStatuses = []DeliveryStatus{DeliveryStatusDelivered, DeliveryStatusUndelivered, DeliveryStatusExpired}
+
+// IsValid checks if the delivery status is one of the known values
+func (s DeliveryStatus) IsValid() bool {
+	return s == DeliveryStatusEnroute || s.IsFinal()
+}
+
+// IsFinal checks if the delivery status ends the delivery
+func (s DeliveryStatus) IsFinal() bool {
+	switch s {
+	case DeliveryStatusDelivered, DeliveryStatusUndelivered, DeliveryStatusExpired:
+		return true
+	}
+	return false
+}
+
+// DeliveryReceipt is a delivery report (DLR) received from a provider
+type DeliveryReceipt struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	Provider          string         `gorm:"size:50;not null;index:idx_delivery_receipts_provider_message" json:"provider"`
+	ProviderMessageID string         `gorm:"size:100;not null;index:idx_delivery_receipts_provider_message" json:"provider_message_id"`
+	Status            DeliveryStatus `gorm:"type:varchar(20);not null" json:"status"`
+	OccurredAt        time.Time      `gorm:"not null" json:"occurred_at"`
+	MessageID         *uuid.UUID     `gorm:"type:uuid;index" json:"message_id,omitempty"` // Empty until the receipt is matched to a message
+	AppliedAt         *time.Time     `json:"applied_at,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// SenderState is the desired state of the message sender shared by all replicas
+type SenderState string
+
+const (
+	SenderStateRunning SenderState = "running"
+	SenderStateStopped SenderState = "stopped"
+)
+
+// IsValid reports whether the state is one of the known sender states
+func (s SenderState) IsValid() bool {
+	return s == SenderStateRunning || s == SenderStateStopped
+}
+
+// SenderSetting persists the desired sender state, the table holds a single row
+type SenderSetting struct {
+	ID        int         `gorm:"primaryKey"`
+	State     SenderState `gorm:"type:varchar(20);not null"`
+	UpdatedAt time.Time
+}
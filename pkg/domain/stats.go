@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// DeliveryOutcome is the result of a single send attempt
+type DeliveryOutcome string
+
+const (
+	DeliveryOutcomeSent   DeliveryOutcome = "sent"
+	DeliveryOutcomeFailed DeliveryOutcome = "failed" // Attempt failed, the message may be retried
+	DeliveryOutcomeDead   DeliveryOutcome = "dead"   // Attempt failed and the message will not be retried
+)
+
+// LatencyBucketsMs upper bounds of the webhook latency histogram, the last bucket catches everything above
+var LatencyBucketsMs = []int{50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+// DeliveryStat is a single send attempt fed into the statistic rollups
+type DeliveryStat struct {
+	At       time.Time
+	Provider string
+	Outcome  DeliveryOutcome
+	Latency  time.Duration
+	Reason   string // Normalized failure reason, empty for sent messages
+}
+
+// MessageStatsRollup holds the per minute delivery counters of a provider
+type MessageStatsRollup struct {
+	Bucket       time.Time `gorm:"primaryKey"`
+	Provider     string    `gorm:"primaryKey;size:50"`
+	SentCount    int64     `gorm:"not null;default:0"`
+	FailedCount  int64     `gorm:"not null;default:0"`
+	DeadCount    int64     `gorm:"not null;default:0"`
+	LatencySumMs int64     `gorm:"not null;default:0"`
+	LatencyCount int64     `gorm:"not null;default:0"`
+}
+
+// MessageStatusRollup counts the messages created in a minute by provider and current status, a trigger on messages moves
+// a message between the counters on every status transition
+type MessageStatusRollup struct {
+	Bucket   time.Time     `gorm:"primaryKey"`
+	Provider string        `gorm:"primaryKey;size:50"`
+	Status   MessageStatus `gorm:"primaryKey;type:varchar(20)"`
+	Count    int64         `gorm:"not null;default:0"`
+}
+
+// LatencyRollup holds the per minute webhook latency histogram of a provider
+type LatencyRollup struct {
+	Bucket       time.Time `gorm:"primaryKey"`
+	Provider     string    `gorm:"primaryKey;size:50"`
+	UpperBoundMs int       `gorm:"primaryKey"`
+	Count        int64     `gorm:"not null;default:0"`
+}
+
+// FailureRollup holds the per minute failure reason counters of a provider
+type FailureRollup struct {
+	Bucket   time.Time `gorm:"primaryKey"`
+	Provider string    `gorm:"primaryKey;size:50"`
+	Reason   string    `gorm:"primaryKey;size:100"`
+	Count    int64     `gorm:"not null;default:0"`
+}
+
+// StatsFilter narrows down the statistics
+type StatsFilter struct {
+	From     time.Time
+	To       time.Time
+	Provider string
+	Interval string // minute, hour or day
+}
+
+// StatsBucket is a single point of the delivery time series
+type StatsBucket struct {
+	Bucket       time.Time `json:"bucket"`
+	Sent         int64     `json:"sent"`
+	Failed       int64     `json:"failed"`
+	Dead         int64     `json:"dead"`
+	LatencySumMs int64     `json:"-"`
+	LatencyCount int64     `json:"-"`
+}
+
+// LatencyBucketCount is a merged bucket of the latency histogram
+type LatencyBucketCount struct {
+	UpperBoundMs int
+	Count        int64
+}
+
+// FailureReasonCount is the number of failures with a reason
+type FailureReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
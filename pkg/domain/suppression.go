@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SuppressionSource tells how a recipient ended up on the suppression list
+type SuppressionSource string
+
+const (
+	SuppressionSourceAPI    SuppressionSource = "api"
+	SuppressionSourceImport SuppressionSource = "import"
+)
+
+// Suppression blocks every message to a recipient until it expires
+type Suppression struct {
+	Phone     string            `gorm:"primaryKey;size:32" json:"phone"`
+	Reason    string            `gorm:"size:255" json:"reason,omitempty"`
+	Source    SuppressionSource `gorm:"type:varchar(20);not null;default:api" json:"source"`
+	ExpiresAt *time.Time        `gorm:"index" json:"expires_at,omitempty"` // Never expires when empty
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// IsActive reports whether the entry still blocks messages at the given time
+func (s Suppression) IsActive(at time.Time) bool {
+	return s.ExpiresAt == nil || s.ExpiresAt.After(at)
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Template is an immutable version of a named message template, editing a template adds a new version
+type Template struct {
+	ID          string            `gorm:"primaryKey;size:64" json:"id"`
+	Version     int               `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Description string            `gorm:"size:255" json:"description,omitempty"`
+	Variants    map[string]string `gorm:"serializer:json;type:jsonb;not null" json:"variants"` // Body per locale with {{.Var}} placeholders
+	CreatedAt   time.Time         `json:"created_at"`
+}
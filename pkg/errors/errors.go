@@ -4,11 +4,25 @@ import "fmt"
 
 // Error types
 var (
-	ErrInvalidRequest    = NewError("invalid request")
-	ErrDatabaseOperation = NewError("database operation failed")
-	ErrMessageNotFound   = NewError("message not found")
-	ErrWebhookFailed     = NewError("webhook request failed")
-	ErrConfiguration     = NewError("configuration error")
+	ErrInvalidRequest       = NewError("invalid request")
+	ErrDatabaseOperation    = NewError("database operation failed")
+	ErrMessageNotFound      = NewError("message not found")
+	ErrWebhookFailed        = NewError("webhook request failed")
+	ErrConfiguration        = NewError("configuration error")
+	ErrStateStore           = NewError("state store operation failed")
+	ErrNotLeader            = NewError("not the sender leader")
+	ErrSenderStopped        = NewError("message sender is stopped")
+	ErrMessageNotPending    = NewError("message is not pending")
+	ErrMessageNotSent       = NewError("message is not sent")
+	ErrUnauthorized         = NewError("unauthorized")
+	ErrSuppressionNotFound  = NewError("suppression not found")
+	ErrRecipientSuppressed  = NewError("recipient is suppressed")
+	ErrTemplateNotFound     = NewError("template not found")
+	ErrDestinationBlocked   = NewError("destination country is blocked")
+	ErrMessageDeferred      = NewError("message is deferred")
+	ErrBlackoutDateNotFound = NewError("blackout date not found")
+	ErrIdempotencyKeyReused = NewError("idempotency key was used with a different request")
+	ErrDuplicateMessage     = NewError("duplicate message")
 )
 
 // AppError represents an application error
@@ -40,3 +54,19 @@ func Wrap(err error, op string) error {
 		Err: err,
 	}
 }
+
+// ValidationError describes an invalid request field, it matches ErrInvalidRequest
+type ValidationError struct {
+	Field   string // Request field, e.g. to
+	Code    string // Machine readable reason, e.g. invalid_length
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Is makes errors.Is(err, ErrInvalidRequest) hold for validation errors
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
@@ -0,0 +1,72 @@
+package phone
+
+// country describes the numbering plan of a region
+type country struct {
+	Region         string   // ISO 3166-1 alpha-2
+	CallingCode    string   // Without the leading +
+	NationalPrefix string   // Trunk prefix dialed before national numbers, e.g. 0
+	Lengths        []int    // Valid lengths of the national significant number
+	Timezone       string   // IANA time zone of the capital, the only one for most regions
+	Mobile         []string // National significant number prefixes of mobile numbers
+	Landline       []string // National significant number prefixes of landline numbers
+}
+
+// countries numbering plans known to the parser, numbers of other countries are rejected
+var countries = []country{
+	{Region: "TR", CallingCode: "90", NationalPrefix: "0", Lengths: []int{10}, Timezone: "Europe/Istanbul",
+		Mobile: []string{"5"}, Landline: []string{"2", "3", "4"}},
+	{Region: "US", CallingCode: "1", NationalPrefix: "1", Lengths: []int{10}, Timezone: "America/New_York"}, // NANP does not separate mobile and landline numbers
+	{Region: "GB", CallingCode: "44", NationalPrefix: "0", Lengths: []int{9, 10}, Timezone: "Europe/London",
+		Mobile: []string{"7"}, Landline: []string{"1", "2"}},
+	{Region: "DE", CallingCode: "49", NationalPrefix: "0", Lengths: []int{6, 7, 8, 9, 10, 11}, Timezone: "Europe/Berlin",
+		Mobile: []string{"15", "16", "17"}, Landline: []string{"2", "3", "4", "5", "6", "7", "8", "9"}},
+	{Region: "FR", CallingCode: "33", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Europe/Paris",
+		Mobile: []string{"6", "7"}, Landline: []string{"1", "2", "3", "4", "5", "9"}},
+	{Region: "NL", CallingCode: "31", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Europe/Amsterdam",
+		Mobile: []string{"6"}, Landline: []string{"1", "2", "3", "4", "5", "7"}},
+	{Region: "BE", CallingCode: "32", NationalPrefix: "0", Lengths: []int{8, 9}, Timezone: "Europe/Brussels",
+		Mobile: []string{"4"}, Landline: []string{"1", "2", "3", "5", "6", "7", "8", "9"}},
+	{Region: "AT", CallingCode: "43", NationalPrefix: "0", Lengths: []int{7, 8, 9, 10, 11, 12, 13}, Timezone: "Europe/Vienna",
+		Mobile: []string{"6"}, Landline: []string{"1", "2", "3", "4", "5", "7"}},
+	{Region: "CH", CallingCode: "41", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Europe/Zurich",
+		Mobile: []string{"7"}, Landline: []string{"2", "3", "4", "5", "6", "9"}},
+	{Region: "IT", CallingCode: "39", NationalPrefix: "", Lengths: []int{6, 7, 8, 9, 10, 11}, Timezone: "Europe/Rome",
+		Mobile: []string{"3"}, Landline: []string{"0"}},
+	{Region: "ES", CallingCode: "34", NationalPrefix: "", Lengths: []int{9}, Timezone: "Europe/Madrid",
+		Mobile: []string{"6", "7"}, Landline: []string{"8", "9"}},
+	{Region: "GR", CallingCode: "30", NationalPrefix: "", Lengths: []int{10}, Timezone: "Europe/Athens",
+		Mobile: []string{"6"}, Landline: []string{"2"}},
+	{Region: "BG", CallingCode: "359", NationalPrefix: "0", Lengths: []int{8, 9}, Timezone: "Europe/Sofia",
+		Mobile: []string{"87", "88", "89", "98", "99"}, Landline: []string{"2", "3", "4", "5", "6", "7", "9"}},
+	{Region: "AZ", CallingCode: "994", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Asia/Baku",
+		Mobile: []string{"10", "40", "50", "51", "55", "60", "70", "77", "99"}, Landline: []string{"1", "2"}},
+	{Region: "GE", CallingCode: "995", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Asia/Tbilisi",
+		Mobile: []string{"5"}, Landline: []string{"3", "4"}},
+	{Region: "RU", CallingCode: "7", NationalPrefix: "8", Lengths: []int{10}, Timezone: "Europe/Moscow",
+		Mobile: []string{"9"}, Landline: []string{"3", "4", "8"}},
+	{Region: "UA", CallingCode: "380", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Europe/Kyiv",
+		Mobile: []string{"39", "5", "6", "9"}, Landline: []string{"3", "4"}},
+	{Region: "AE", CallingCode: "971", NationalPrefix: "0", Lengths: []int{8, 9}, Timezone: "Asia/Dubai",
+		Mobile: []string{"5"}, Landline: []string{"2", "3", "4", "6", "7", "9"}},
+	{Region: "SA", CallingCode: "966", NationalPrefix: "0", Lengths: []int{9}, Timezone: "Asia/Riyadh",
+		Mobile: []string{"5"}, Landline: []string{"1"}},
+	{Region: "IQ", CallingCode: "964", NationalPrefix: "0", Lengths: []int{8, 9, 10}, Timezone: "Asia/Baghdad",
+		Mobile: []string{"7"}, Landline: []string{"1", "2", "3", "4", "5", "6"}},
+}
+
+// Lookups built from the countries table
+var (
+	byRegion      = make(map[string]*country, len(countries))
+	byCallingCode = make(map[string]*country, len(countries))
+)
+
+func init() {
+	for i := range countries {
+		c := &countries[i]
+		byRegion[c.Region] = c
+		// Several regions can share a calling code, the first listed owns it
+		if _, ok := byCallingCode[c.CallingCode]; !ok {
+			byCallingCode[c.CallingCode] = c
+		}
+	}
+}
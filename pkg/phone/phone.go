@@ -0,0 +1,198 @@
+// Package phone parses phone numbers in local and international formats and normalizes them to E.164
+package phone
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Type classifies a phone number
+type Type string
+
+const (
+	TypeMobile   Type = "mobile"
+	TypeLandline Type = "landline"
+	TypeUnknown  Type = "unknown" // Valid number outside the known mobile and landline ranges
+)
+
+// Error codes of ParseError
+const (
+	CodeEmpty              = "empty"
+	CodeInvalidCharacters  = "invalid_characters"
+	CodeUnknownRegion      = "unknown_region"
+	CodeUnknownCallingCode = "unknown_calling_code"
+	CodeInvalidLength      = "invalid_length"
+)
+
+// ParseError explains why a phone number was rejected
+type ParseError struct {
+	Code    string
+	Input   string
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return e.Message
+}
+
+// Number is a parsed and validated phone number
+type Number struct {
+	E164           string `json:"e164"`            // +905551234567
+	CallingCode    string `json:"calling_code"`    // 90
+	Region         string `json:"region"`          // TR
+	NationalNumber string `json:"national_number"` // 5551234567
+	Type           Type   `json:"type"`
+}
+
+// Parse parses a number written in international format (+90..., 0090...) or in the national
+// format of defaultRegion (0555..., 555...), and validates its length for the country
+func Parse(input, defaultRegion string) (Number, error) {
+	trimmed := strings.TrimSpace(input)
+	if trimmed == "" {
+		return Number{}, &ParseError{Code: CodeEmpty, Input: input, Message: "phone number is empty"}
+	}
+
+	digits, international, err := extractDigits(trimmed)
+	if err != nil {
+		return Number{}, err
+	}
+
+	if international {
+		return parseInternational(input, digits)
+	}
+
+	region := byRegion[strings.ToUpper(defaultRegion)]
+	if region == nil {
+		return Number{}, &ParseError{Code: CodeUnknownRegion, Input: input, Message: fmt.Sprintf("unknown default region %q", defaultRegion)}
+	}
+
+	// The trunk prefix is dropped whenever the rest is a valid number, in regions with variable lengths (DE, AT) the digits
+	// with the prefix can have a valid length too. It is only kept when the number is invalid without it.
+	national := digits
+	if region.NationalPrefix != "" && strings.HasPrefix(digits, region.NationalPrefix) {
+		if stripped := strings.TrimPrefix(digits, region.NationalPrefix); region.validLength(stripped) {
+			national = stripped
+		}
+	}
+	if !region.validLength(national) && strings.HasPrefix(digits, region.CallingCode) {
+		// Written internationally without the +, e.g. 905551234567
+		return parseInternational(input, digits)
+	}
+	return region.number(input, national)
+}
+
+// Normalize parses the number and returns it in E.164
+func Normalize(input, defaultRegion string) (string, error) {
+	number, err := Parse(input, defaultRegion)
+	if err != nil {
+		return "", err
+	}
+	return number.E164, nil
+}
+
+// RegionOf returns the region of an E.164 number, empty when the calling code is not known
+func RegionOf(e164 string) string {
+	digits := strings.TrimPrefix(e164, "+")
+	for size := 1; size <= 3 && size <= len(digits); size++ {
+		if c, ok := byCallingCode[digits[:size]]; ok {
+			return c.Region
+		}
+	}
+	return ""
+}
+
+// TimezoneOf returns the IANA time zone of the region, empty when the region is not known
+func TimezoneOf(region string) string {
+	if c, ok := byRegion[strings.ToUpper(region)]; ok {
+		return c.Timezone
+	}
+	return ""
+}
+
+// extractDigits drops the formatting characters and reports whether the number has an international prefix
+func extractDigits(input string) (string, bool, error) {
+	international := strings.HasPrefix(input, "+")
+
+	var digits strings.Builder
+	for _, r := range strings.TrimPrefix(input, "+") {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, &ParseError{Code: CodeInvalidCharacters, Input: input, Message: fmt.Sprintf("phone number contains %q", r)}
+		}
+	}
+
+	result := digits.String()
+	if !international && strings.HasPrefix(result, "00") {
+		international = true
+		result = result[2:]
+	}
+	return result, international, nil
+}
+
+// parseInternational splits the calling code off the digits
+func parseInternational(input, digits string) (Number, error) {
+	for size := 1; size <= 3 && size <= len(digits); size++ {
+		if c, ok := byCallingCode[digits[:size]]; ok {
+			return c.number(input, digits[size:])
+		}
+	}
+	return Number{}, &ParseError{Code: CodeUnknownCallingCode, Input: input, Message: "unknown or unsupported country calling code"}
+}
+
+// number validates the national significant number and builds the result
+func (c *country) number(input, national string) (Number, error) {
+	if !c.validLength(national) {
+		return Number{}, &ParseError{
+			Code:    CodeInvalidLength,
+			Input:   input,
+			Message: fmt.Sprintf("%s numbers have %s digits after +%s, got %d", c.Region, describeLengths(c.Lengths), c.CallingCode, len(national)),
+		}
+	}
+
+	return Number{
+		E164:           "+" + c.CallingCode + national,
+		CallingCode:    c.CallingCode,
+		Region:         c.Region,
+		NationalNumber: national,
+		Type:           c.classify(national),
+	}, nil
+}
+
+// validLength reports whether the national significant number has a valid length
+func (c *country) validLength(national string) bool {
+	return slices.Contains(c.Lengths, len(national))
+}
+
+// classify tells mobile and landline numbers apart by their prefix
+func (c *country) classify(national string) Type {
+	for _, prefix := range c.Mobile {
+		if strings.HasPrefix(national, prefix) {
+			return TypeMobile
+		}
+	}
+	for _, prefix := range c.Landline {
+		if strings.HasPrefix(national, prefix) {
+			return TypeLandline
+		}
+	}
+	return TypeUnknown
+}
+
+// describeLengths formats the valid lengths for error messages
+func describeLengths(lengths []int) string {
+	if len(lengths) == 1 {
+		return fmt.Sprint(lengths[0])
+	}
+	if lengths[len(lengths)-1]-lengths[0] == len(lengths)-1 {
+		return fmt.Sprintf("%d-%d", lengths[0], lengths[len(lengths)-1])
+	}
+	parts := make([]string, len(lengths))
+	for i, length := range lengths {
+		parts[i] = fmt.Sprint(length)
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,177 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultRegion string
+		want          Number
+	}{
+		{
+			name:  "international",
+			input: "+90 555 123 45 67",
+			want:  Number{E164: "+905551234567", CallingCode: "90", Region: "TR", NationalNumber: "5551234567", Type: TypeMobile},
+		},
+		{
+			name:  "international with 00",
+			input: "0090 (555) 123-45-67",
+			want:  Number{E164: "+905551234567", CallingCode: "90", Region: "TR", NationalNumber: "5551234567", Type: TypeMobile},
+		},
+		{
+			name:          "national with trunk prefix",
+			input:         "0555 123 45 67",
+			defaultRegion: "TR",
+			want:          Number{E164: "+905551234567", CallingCode: "90", Region: "TR", NationalNumber: "5551234567", Type: TypeMobile},
+		},
+		{
+			name:          "national without trunk prefix",
+			input:         "5551234567",
+			defaultRegion: "tr",
+			want:          Number{E164: "+905551234567", CallingCode: "90", Region: "TR", NationalNumber: "5551234567", Type: TypeMobile},
+		},
+		{
+			name:          "international without plus",
+			input:         "905551234567",
+			defaultRegion: "TR",
+			want:          Number{E164: "+905551234567", CallingCode: "90", Region: "TR", NationalNumber: "5551234567", Type: TypeMobile},
+		},
+		{
+			name:          "landline",
+			input:         "0212 555 12 34",
+			defaultRegion: "TR",
+			want:          Number{E164: "+902125551234", CallingCode: "90", Region: "TR", NationalNumber: "2125551234", Type: TypeLandline},
+		},
+		{
+			name:          "region without mobile ranges",
+			input:         "(212) 555-0123",
+			defaultRegion: "US",
+			want:          Number{E164: "+12125550123", CallingCode: "1", Region: "US", NationalNumber: "2125550123", Type: TypeUnknown},
+		},
+		{
+			name:          "trunk prefix 1",
+			input:         "1 212 555 0123",
+			defaultRegion: "US",
+			want:          Number{E164: "+12125550123", CallingCode: "1", Region: "US", NationalNumber: "2125550123", Type: TypeUnknown},
+		},
+		{
+			name:          "trunk prefix 8",
+			input:         "8 912 345 67 89",
+			defaultRegion: "RU",
+			want:          Number{E164: "+79123456789", CallingCode: "7", Region: "RU", NationalNumber: "9123456789", Type: TypeMobile},
+		},
+		{
+			name:          "trunk prefix dropped with variable lengths",
+			input:         "030 1234567",
+			defaultRegion: "DE",
+			want:          Number{E164: "+49301234567", CallingCode: "49", Region: "DE", NationalNumber: "301234567", Type: TypeLandline},
+		},
+		{
+			name:  "leading zero kept without trunk prefix",
+			input: "+39 06 1234 5678",
+			want:  Number{E164: "+390612345678", CallingCode: "39", Region: "IT", NationalNumber: "0612345678", Type: TypeLandline},
+		},
+		{
+			name:  "three digit calling code",
+			input: "+994 50 123 45 67",
+			want:  Number{E164: "+994501234567", CallingCode: "994", Region: "AZ", NationalNumber: "501234567", Type: TypeMobile},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.defaultRegion)
+			if err != nil {
+				t.Fatalf("Parse(%q, %q) error = %v", tt.input, tt.defaultRegion, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.input, tt.defaultRegion, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		defaultRegion string
+		code          string
+	}{
+		{name: "empty", input: "", defaultRegion: "TR", code: CodeEmpty},
+		{name: "blank", input: "   ", defaultRegion: "TR", code: CodeEmpty},
+		{name: "letters", input: "+90 555 ABC 45 67", defaultRegion: "TR", code: CodeInvalidCharacters},
+		{name: "national without default region", input: "0555 123 45 67", code: CodeUnknownRegion},
+		{name: "unknown default region", input: "0555 123 45 67", defaultRegion: "XX", code: CodeUnknownRegion},
+		{name: "unknown calling code", input: "+888 1234 5678", code: CodeUnknownCallingCode},
+		{name: "international too short", input: "+90 555 123", code: CodeInvalidLength},
+		{name: "international too long", input: "+90 555 123 45 678", code: CodeInvalidLength},
+		{name: "national too short", input: "0555 123", defaultRegion: "TR", code: CodeInvalidLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input, tt.defaultRegion)
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse(%q, %q) error = %v, want a *ParseError", tt.input, tt.defaultRegion, err)
+			}
+			if parseErr.Code != tt.code {
+				t.Errorf("Parse(%q, %q) code = %q, want %q", tt.input, tt.defaultRegion, parseErr.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input         string
+		defaultRegion string
+		want          string
+		wantErr       bool
+	}{
+		{input: "+905551234567", want: "+905551234567"},
+		{input: "0555 123 45 67", defaultRegion: "TR", want: "+905551234567"},
+		{input: "07700 900123", defaultRegion: "GB", want: "+447700900123"},
+		{input: "12345", defaultRegion: "TR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Normalize(tt.input, tt.defaultRegion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize(%q, %q) error = %v, wantErr %v", tt.input, tt.defaultRegion, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.input, tt.defaultRegion, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionOf(t *testing.T) {
+	tests := []struct {
+		e164 string
+		want string
+	}{
+		{e164: "+905551234567", want: "TR"},
+		{e164: "+12125550123", want: "US"},
+		{e164: "+79123456789", want: "RU"},
+		{e164: "+994501234567", want: "AZ"},
+		{e164: "+8881234", want: ""},
+		{e164: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.e164, func(t *testing.T) {
+			if got := RegionOf(tt.e164); got != tt.want {
+				t.Errorf("RegionOf(%q) = %q, want %q", tt.e164, got, tt.want)
+			}
+		})
+	}
+}
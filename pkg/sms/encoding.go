@@ -0,0 +1,113 @@
+// Package sms analyzes message content the way carriers encode and split it
+package sms
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding is the character encoding a message is sent with
+type Encoding string
+
+const (
+	EncodingGSM7 Encoding = "gsm7" // GSM 03.38 default alphabet, 7 bits per character
+	EncodingUCS2 Encoding = "ucs2" // UTF-16, used as soon as one character is outside GSM-7
+)
+
+// Segment capacities, multipart messages lose room to the concatenation header
+const (
+	GSM7SingleSegment = 160
+	GSM7MultiSegment  = 153
+	UCS2SingleSegment = 70
+	UCS2MultiSegment  = 67
+)
+
+// gsm7Basic characters of the GSM 03.38 default alphabet, one septet each
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters of the extension table, two septets each because of the escape
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// Analysis describes how a content is encoded and split into segments
+type Analysis struct {
+	Encoding   Encoding `json:"encoding"`
+	Characters int      `json:"characters"`              // Characters as the user sees them
+	Units      int      `json:"units"`                   // Septets for GSM-7, UTF-16 code units for UCS-2
+	Segments   int      `json:"segments"`                // Parts the carrier splits the message into
+	PerSegment int      `json:"per_segment"`             // Capacity of a segment in units
+	NonGSM     []string `json:"non_gsm_chars,omitempty"` // Characters that force UCS-2
+}
+
+// Analyze returns the encoding, character count and segment count of the content
+func Analyze(content string) Analysis {
+	analysis := Analysis{
+		Encoding:   EncodingGSM7,
+		Characters: utf8.RuneCountInString(content),
+	}
+
+	seen := make(map[rune]bool)
+	for _, r := range content {
+		if !IsGSM7(r) && !seen[r] {
+			seen[r] = true
+			analysis.NonGSM = append(analysis.NonGSM, string(r))
+			analysis.Encoding = EncodingUCS2
+		}
+	}
+
+	single, multi, width := GSM7SingleSegment, GSM7MultiSegment, gsm7Width
+	if analysis.Encoding == EncodingUCS2 {
+		single, multi, width = UCS2SingleSegment, UCS2MultiSegment, ucs2Width
+	}
+
+	for _, r := range content {
+		analysis.Units += width(r)
+	}
+
+	switch {
+	case analysis.Units == 0:
+		analysis.PerSegment = single
+	case analysis.Units <= single:
+		analysis.Segments = 1
+		analysis.PerSegment = single
+	default:
+		analysis.Segments = countSegments(content, multi, width)
+		analysis.PerSegment = multi
+	}
+	return analysis
+}
+
+// IsGSM7 reports whether the character can be sent with the GSM-7 alphabet
+func IsGSM7(r rune) bool {
+	return strings.ContainsRune(gsm7Basic, r) || strings.ContainsRune(gsm7Extension, r)
+}
+
+// countSegments fills segments character by character, an escape sequence or surrogate pair is never split
+func countSegments(content string, capacity int, width func(rune) int) int {
+	segments, used := 1, 0
+	for _, r := range content {
+		w := width(r)
+		if used+w > capacity {
+			segments++
+			used = 0
+		}
+		used += w
+	}
+	return segments
+}
+
+// gsm7Width septets of a GSM-7 character
+func gsm7Width(r rune) int {
+	if strings.ContainsRune(gsm7Extension, r) {
+		return 2
+	}
+	return 1
+}
+
+// ucs2Width UTF-16 code units of a character
+func ucs2Width(r rune) int {
+	if r > 0xFFFF {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,69 @@
+package sms
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Analysis
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    Analysis{Encoding: EncodingGSM7, PerSegment: GSM7SingleSegment},
+		},
+		{
+			name:    "gsm7 single segment",
+			content: "Hello!",
+			want:    Analysis{Encoding: EncodingGSM7, Characters: 6, Units: 6, Segments: 1, PerSegment: GSM7SingleSegment},
+		},
+		{
+			name:    "gsm7 full single segment",
+			content: strings.Repeat("a", 160),
+			want:    Analysis{Encoding: EncodingGSM7, Characters: 160, Units: 160, Segments: 1, PerSegment: GSM7SingleSegment},
+		},
+		{
+			name:    "gsm7 multipart",
+			content: strings.Repeat("a", 161),
+			want:    Analysis{Encoding: EncodingGSM7, Characters: 161, Units: 161, Segments: 2, PerSegment: GSM7MultiSegment},
+		},
+		{
+			name:    "extension characters take two septets",
+			content: "€[]",
+			want:    Analysis{Encoding: EncodingGSM7, Characters: 3, Units: 6, Segments: 1, PerSegment: GSM7SingleSegment},
+		},
+		{
+			name:    "escape sequence is never split",
+			content: strings.Repeat("a", 152) + "€" + strings.Repeat("a", 152),
+			want:    Analysis{Encoding: EncodingGSM7, Characters: 305, Units: 306, Segments: 3, PerSegment: GSM7MultiSegment},
+		},
+		{
+			name:    "one character outside gsm7 forces ucs2",
+			content: "Merhaba ğ",
+			want:    Analysis{Encoding: EncodingUCS2, Characters: 9, Units: 9, Segments: 1, PerSegment: UCS2SingleSegment, NonGSM: []string{"ğ"}},
+		},
+		{
+			name:    "ucs2 multipart lists each character once",
+			content: strings.Repeat("ş", 71),
+			want:    Analysis{Encoding: EncodingUCS2, Characters: 71, Units: 71, Segments: 2, PerSegment: UCS2MultiSegment, NonGSM: []string{"ş"}},
+		},
+		{
+			name:    "surrogate pairs take two units",
+			content: "Hi 😀",
+			want:    Analysis{Encoding: EncodingUCS2, Characters: 4, Units: 5, Segments: 1, PerSegment: UCS2SingleSegment, NonGSM: []string{"😀"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package sms
+
+import "strings"
+
+// commonTable maps characters outside GSM-7 to their closest GSM-7 form for every language
+var commonTable = []string{
+	"á", "a", "â", "a", "ã", "a", "ç", "c", "ê", "e", "ë", "e", "í", "i", "î", "i", "ï", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "û", "u",
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ê", "E", "È", "E", "Ë", "E", "Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ú", "U", "Ù", "U", "Û", "U",
+	"‘", "'", "’", "'", "“", "\"", "”", "\"", "–", "-", "—", "-", "…", "...",
+}
+
+// languageTables add the characters of a language on top of the common table
+var languageTables = map[string][]string{
+	"tr": {"ğ", "g", "Ğ", "G", "ı", "i", "İ", "I", "ş", "s", "Ş", "S"},
+}
+
+// replacers per language, languages without a table use the common one
+var (
+	commonReplacer    = strings.NewReplacer(commonTable...)
+	languageReplacers = make(map[string]*strings.Replacer, len(languageTables))
+)
+
+func init() {
+	for language, table := range languageTables {
+		languageReplacers[language] = strings.NewReplacer(append(table, commonTable...)...)
+	}
+}
+
+// Transliterate replaces the characters that have a GSM-7 equivalent in the language, the rest is left as is
+func Transliterate(content, language string) string {
+	if replacer, ok := languageReplacers[language]; ok {
+		return replacer.Replace(content)
+	}
+	return commonReplacer.Replace(content)
+}
@@ -0,0 +1,27 @@
+package sms
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		language string
+		want     string
+	}{
+		{name: "gsm7 content is unchanged", content: "Good morning", language: "en", want: "Good morning"},
+		{name: "language table", content: "Günaydın İstanbul, şimdi ğ", language: "tr", want: "Günaydin Istanbul, simdi g"},
+		{name: "common table applies to every language", content: "Olá, “você” está…", language: "pt", want: "Ola, \"voce\" esta..."},
+		{name: "common table applies with a language table", content: "Çok güzel — ş", language: "tr", want: "Çok güzel - s"},
+		{name: "language characters need their table", content: "ş", language: "en", want: "ş"},
+		{name: "characters without an equivalent are kept", content: "Hi 😀", language: "tr", want: "Hi 😀"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transliterate(tt.content, tt.language); got != tt.want {
+				t.Errorf("Transliterate(%q, %q) = %q, want %q", tt.content, tt.language, got, tt.want)
+			}
+		})
+	}
+}